@@ -0,0 +1,79 @@
+package i3config
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Argv describes a program to run as a list of arguments instead of a shell
+// string. It is quoted for /bin/sh when it is turned into a command, so the
+// program receives exactly the arguments given.
+type Argv struct {
+	args []string
+	env  []string
+	dir  string
+}
+
+func Args(name string, args ...string) *Argv {
+	return &Argv{
+		args: append([]string{name}, args...),
+		env:  []string{},
+	}
+}
+
+func (a *Argv) Env(key, value string) *Argv {
+	a.env = append(a.env, key+"="+value)
+	return a
+}
+
+func (a *Argv) Dir(dir string) *Argv {
+	a.dir = dir
+	return a
+}
+
+// String returns the shell command line that runs the program.
+func (a *Argv) String() string {
+	words := []string{}
+	for _, env := range a.env {
+		key, value, _ := strings.Cut(env, "=")
+		words = append(words, key+"="+shellQuote(value))
+	}
+	for _, arg := range a.args {
+		words = append(words, shellQuote(arg))
+	}
+	src := strings.Join(words, " ")
+	if a.dir != "" {
+		src = "cd " + shellQuote(a.dir) + " && " + src
+	}
+	return src
+}
+
+// cmd returns the program to run directly, without a shell.
+func (a *Argv) cmd() *exec.Cmd {
+	cmd := exec.Command(a.args[0], a.args[1:]...)
+	cmd.Dir = a.dir
+	if len(a.env) > 0 {
+		cmd.Env = append(os.Environ(), a.env...)
+	}
+	return cmd
+}
+
+func (a *Argv) Exec() *Command {
+	return Exec(a.String())
+}
+
+// ExecArgs runs name with args, quoting each of them for the shell.
+func ExecArgs(name string, args ...string) *Command {
+	return Args(name, args...).Exec()
+}
+
+var shellSafeRegExp = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+func shellQuote(str string) string {
+	if shellSafeRegExp.MatchString(str) {
+		return str
+	}
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
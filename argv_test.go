@@ -0,0 +1,73 @@
+package i3config
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// i3ExecString reads a generated exec command the way i3 does when it is in a
+// config file and returns the string handed to /bin/sh.
+func i3ExecString(t *testing.T, cmd *Command) string {
+	t.Helper()
+	src := unescapeConfig(cmd.Generate())
+
+	src = strings.TrimPrefix(src, "exec ")
+	src = strings.TrimPrefix(src, "--no-startup-id ")
+	value, rest, err := parseCommandString(src, false)
+	require.NoError(t, err)
+	require.Equal(t, "", rest)
+	return value
+}
+
+func shArgv(t *testing.T, src string) []string {
+	t.Helper()
+	b, err := exec.Command("/bin/sh", "-c", src).Output()
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00")
+}
+
+func TestExecArgsRoundTrip(t *testing.T) {
+	testCases := [][]string{
+		{"plain", "words"},
+		{"with space", "", "it's", `"double"`},
+		{`back\slash`, `\"`, `\\"`, "$HOME", "~", "`id`", "$(id)"},
+		{"a;b", "a,b", "a|b", "a&&b", "#hash", "*", "[x]"},
+		{"emacsclient", "-c", "-e", `(find-file "/tmp")`},
+	}
+	for _, args := range testCases {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			cmd := ExecArgs("printf", append([]string{`%s\0`}, args...)...)
+			assert.Equal(t, args, shArgv(t, i3ExecString(t, cmd)))
+		})
+	}
+}
+
+func TestExecArgsEnvAndDir(t *testing.T) {
+	dir := t.TempDir()
+	cmd := Args("sh", "-c", `printf '%s\0' "$FOO" "$PWD"`).
+		Env("FOO", "it's $HOME").
+		Dir(dir).
+		Exec().
+		NoStartupID()
+
+	assert.True(t, strings.HasPrefix(cmd.Generate(), "exec --no-startup-id "))
+
+	assert.Equal(t, []string{"it's $HOME", dir}, shArgv(t, i3ExecString(t, cmd)))
+}
+
+func TestArgvCmdEnv(t *testing.T) {
+	dir := t.TempDir()
+	cmd := Args("sh", "-c", `printf '%s\0' "$FOO" "$PWD"`).
+		Env("FOO", "it's a b").
+		Dir(dir).
+		cmd()
+
+	assert.Equal(t, "FOO=it's a b", cmd.Env[len(cmd.Env)-1])
+	b, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "it's a b\x00"+dir+"\x00", string(b))
+}
@@ -34,7 +34,20 @@ type BarWorkspaceColor struct {
 	Text       Color
 }
 
+func (b *BarWorkspaceColor) check(name string, vars map[string]string) []string {
+	if b == nil {
+		return []string{}
+	}
+	problems := []string{}
+	problems = checkColor(problems, vars, b.Border, name+" border")
+	problems = checkColor(problems, vars, b.Background, name+" background")
+	return checkColor(problems, vars, b.Text, name+" text")
+}
+
 func (b *BarWorkspaceColor) Generate() string {
+	if b == nil {
+		return ""
+	}
 	return fmt.Sprintf("%s %s %s",
 		b.Border.Generate(),
 		b.Background.Generate(),
@@ -52,16 +65,36 @@ type BarColorConfig struct {
 	UrgentWorkspace   *BarWorkspaceColor `i3:"urgent_workspace"`
 }
 
+// check reports invalid colors, unset colors are left out of the config.
+func (b *BarColorConfig) check(vars map[string]string) []string {
+	problems := []string{}
+	checkOptional := func(c Color, name string) {
+		if c != "" {
+			problems = checkColor(problems, vars, c, name)
+		}
+	}
+	checkOptional(b.Background, "bar background")
+	checkOptional(b.StatusLine, "bar statusline")
+	checkOptional(b.Separator, "bar separator")
+	problems = append(problems, b.FocusedWorkspace.check("bar focused_workspace", vars)...)
+	problems = append(problems, b.ActiveWorkspace.check("bar active_workspace", vars)...)
+	problems = append(problems, b.InactiveWorkspace.check("bar inactive_workspace", vars)...)
+	return append(problems, b.UrgentWorkspace.check("bar urgent_workspace", vars)...)
+}
+
 func (b *BarColorConfig) Generate() string {
 	lines := []string{}
 	EachKey(b, func(key, value string) {
-		lines = append(lines, key+" "+value)
+		if value != "" {
+			lines = append(lines, key+" "+value)
+		}
 	})
 	return "colors {\n" + indent(strings.Join(lines, "\n")) + "\n}"
 }
 
 type BarConfig struct {
 	*Config
+	parent *Config
 }
 
 func (b *BarConfig) Colors(c *BarColorConfig) {
@@ -76,23 +109,79 @@ const (
 )
 
 func (b *BarConfig) Position(p BarPosition) {
+	if !strings.EqualFold(string(p), string(Top)) && !strings.EqualFold(string(p), string(Bottom)) {
+		b.problem(callerSource(), "unknown bar position %q", p)
+	}
 	b.raw("position " + string(p))
 }
 
+type statusCommand string
+
+func (s statusCommand) Generate() string {
+	return "status_command " + string(s)
+}
+
 func (b *BarConfig) StatusCommand(command string) {
-	b.raw("status_command " + command)
+	if strings.TrimSpace(command) == "" {
+		b.problem(callerSource(), "empty status_command")
+	}
+	b.AddLine(statusCommand(command))
 }
 func (b *BarConfig) TrayOutput(display string) {
 	b.raw("tray_output " + display)
 }
 
+func (b *BarConfig) has(match func(g Generator) bool) bool {
+	for _, l := range b.lines {
+		if match(l.generator) {
+			return true
+		}
+	}
+	return false
+}
+
+// themeColors returns the colors of the parent's theme when the bar does not
+// set its own, otherwise nil.
+func (b *BarConfig) themeColors() *BarColorConfig {
+	t := b.parent.theme
+	if t == nil || b.has(func(g Generator) bool {
+		_, ok := g.(*BarColorConfig)
+		return ok
+	}) {
+		return nil
+	}
+	return t.BarColorConfig()
+}
+
+// themeLines returns the colors and font of the parent's theme that the bar
+// does not set itself.
+func (b *BarConfig) themeLines() string {
+	t := b.parent.theme
+	if t == nil {
+		return ""
+	}
+	src := ""
+	hasFont := b.has(func(g Generator) bool {
+		_, ok := g.(fontLine)
+		return ok
+	})
+	if !hasFont && t.barFont() != "" {
+		src += fontLine(t.barFont()).Generate() + "\n"
+	}
+	if colors := b.themeColors(); colors != nil {
+		src += colors.Generate() + "\n"
+	}
+	return src
+}
+
 func (b *BarConfig) Generate() string {
-	return fmt.Sprintf("bar {\n%s\n}", indent(b.Config.Generate()))
+	return fmt.Sprintf("bar {\n%s\n}", indent(b.Config.Generate()+b.themeLines()))
 }
 
 func (c *Config) Bar(bar func(*BarConfig)) {
 	b := &BarConfig{
 		Config: c.newSubConfig(),
+		parent: c,
 	}
 	bar(b)
 	c.AddLine(b)
@@ -6,55 +6,194 @@ import (
 )
 
 type Bind struct {
-	bindType string
-	keys     string
-	release  bool
-	commands []*Command
-	alias    []string
+	bindType        string
+	keys            KeyCombo
+	release         bool
+	border          bool
+	wholeWindow     bool
+	excludeTitlebar bool
+	toCode          bool
+	mods            []string
+	commands        []*Command
+	alias           []KeyCombo
+	description     string
+	translation     *keyTranslation
+	// config is the config the binding was added to, it records problems
+	// found while the binding is built
+	config *Config
 }
 
-func (c *Config) newBind(bindType, keys string, commands []*Command) *Bind {
-	b := &Bind{
+func newBind(bindType string, keys KeyCombo, commands []*Command) *Bind {
+	return &Bind{
 		bindType: bindType,
 		keys:     keys,
 		release:  false,
 		commands: commands,
-		alias:    []string{},
+		alias:    []KeyCombo{},
 	}
+}
 
-	c.AddLine(b)
-
+func (c *Config) bind(bindType string, keys KeyCombo, commands []*Command, source Source) *Bind {
+	b := newBind(bindType, keys, commands)
+	b.translation = c.translation
+	b.config = c
+	c.addLine(b, source)
 	return b
 }
+
+// BindSym binds a keysym or a mouse button (button1 to button9).
 func (c *Config) BindSym(keys string, commands ...*Command) *Bind {
-	return c.newBind("bindsym", keys, commands)
+	combo, source := parseKeyCombo(keys), callerSource()
+	if err := combo.checkKeysym(); err != nil {
+		c.problem(source, "%v", err)
+	}
+	return c.bind("bindsym", combo, commands, source)
 }
 
+// BindCode binds an X keycode, use Mod to add modifiers.
 func (c *Config) BindCode(code int, commands ...*Command) *Bind {
-	return c.newBind("bindsym", fmt.Sprint(code), commands)
+	return c.bind("bindcode", KeyCombo{Modifiers: []Modifier{}, Key: fmt.Sprint(code)}, commands, callerSource())
 }
 
-func (b *Bind) Release() {
+func (b *Bind) Release() *Bind {
 	b.release = true
+	return b
+}
+
+// Border makes a mouse binding also trigger on the window border.
+func (b *Bind) Border() *Bind {
+	b.border = true
+	return b
 }
 
-func (b *Bind) Alias(keys string) {
-	b.alias = append(b.alias, keys)
+// WholeWindow makes a mouse binding trigger anywhere on the window, not only
+// on the title bar.
+func (b *Bind) WholeWindow() *Bind {
+	b.wholeWindow = true
+	return b
 }
 
-func (b *Bind) Generate() string {
-	release := ""
+// ExcludeTitlebar stops a mouse binding from triggering on the title bar.
+func (b *Bind) ExcludeTitlebar() *Bind {
+	b.excludeTitlebar = true
+	return b
+}
+
+// ToCode asks for a keysym binding to be written as the keycode that
+// produces it on the keymap set with Config.Keymap, so it stays on the same
+// physical key across layouts.
+func (b *Bind) ToCode() *Bind {
+	b.toCode = true
+	return b
+}
+
+// Mod adds modifiers or $variables to the binding and all of its aliases.
+func (b *Bind) Mod(mods ...string) *Bind {
+	b.mods = append(b.mods, mods...)
+	return b
+}
+
+func (b *Bind) withMods(k KeyCombo) KeyCombo {
+	k.Modifiers = append([]Modifier{}, k.Modifiers...)
+	for _, mod := range b.mods {
+		m, ok := lookupModifier(mod)
+		if !ok {
+			m = Modifier(mod)
+		}
+		if !k.HasModifier(m) {
+			k.Modifiers = append(k.Modifiers, m)
+		}
+	}
+	k.sort()
+	return k
+}
+
+// combos returns every key combination the binding is written with, aliases
+// first.
+func (b *Bind) combos() []KeyCombo {
+	combos := []KeyCombo{}
+	for _, k := range b.alias {
+		combos = append(combos, b.withMods(k))
+	}
+	return append(combos, b.withMods(b.keys))
+}
+
+// Describe sets the text shown for the binding in cheat sheets.
+func (b *Bind) Describe(description string) *Bind {
+	b.description = description
+	return b
+}
+
+// Group limits the binding to the XKB group (layout) n, from 1 to 4.
+func (b *Bind) Group(n int) *Bind {
+	return b.Mod(fmt.Sprintf("Group%d", n))
+}
+
+func (b *Bind) Alias(keys string) *Bind {
+	combo := parseKeyCombo(keys)
+	if b.bindType == "bindsym" && b.config != nil {
+		if err := combo.checkKeysym(); err != nil {
+			b.config.problem(callerSource(), "%v", err)
+		}
+	}
+	b.alias = append(b.alias, combo)
+	return b
+}
+
+// wantsCode reports whether keys should be bound by keycode, with ToCode on
+// the binding or the config. Mouse buttons have no keycode.
+func (b *Bind) wantsCode(keys KeyCombo) bool {
+	if b.bindType != "bindsym" || keys.IsButton() {
+		return false
+	}
+	return b.toCode || (b.translation != nil && b.translation.all)
+}
+
+// keycode translates keys with the keymap set with Config.Keymap.
+func (b *Bind) keycode(keys KeyCombo) (KeyCombo, error) {
+	if b.translation == nil || b.translation.keymap == nil {
+		return keys, fmt.Errorf("no keymap")
+	}
+	return b.translation.keymap.Translate(keys)
+}
+
+func (b *Bind) flags() string {
+	flags := ""
 	if b.release {
-		release = "--release "
+		flags += "--release "
+	}
+	if b.border {
+		flags += "--border "
 	}
+	if b.wholeWindow {
+		flags += "--whole-window "
+	}
+	if b.excludeTitlebar {
+		flags += "--exclude-titlebar "
+	}
+	return flags
+}
+
+// Generate writes keysym bindings that should be bound by keycode as
+// bindcode when the keymap can translate them, otherwise i3 translates them
+// with --to-code using the layout it runs with.
+func (b *Bind) Generate() string {
 	strCommands := []string{}
 
 	for _, cmd := range b.commands {
 		strCommands = append(strCommands, cmd.Generate())
 	}
 	src := ""
-	for _, keys := range append(b.alias, b.keys) {
-		src += b.bindType + " " + release + keys + " " + strings.Join(strCommands, "; ") + "\n"
+	for _, keys := range b.combos() {
+		bindType, flags := b.bindType, b.flags()
+		if b.wantsCode(keys) {
+			if code, err := b.keycode(keys); err == nil {
+				bindType, keys = "bindcode", code
+			} else {
+				flags = "--to-code " + flags
+			}
+		}
+		src += bindType + " " + flags + keys.String() + " " + strings.Join(strCommands, "; ") + "\n"
 	}
 
 	return src[:len(src)-1]
@@ -0,0 +1,41 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindGenerate(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindCode(38, Kill).Mod("$mod", "shift")
+	c.BindSym("button3", FloatingEnabled).WholeWindow().Border().Mod("$mod")
+	c.BindSym("button2", Kill).Release().ExcludeTitlebar()
+	c.BindSym("$mod+q", Kill).Group(2).Alias("$mod+w")
+
+	assert.Equal(t, `set $mod Mod4
+bindcode $mod+Shift+38 kill
+bindsym --border --whole-window $mod+button3 floating enabled
+bindsym --release --exclude-titlebar button2 kill
+bindsym $mod+Group2+w kill
+bindsym $mod+Group2+q kill
+`, c.Generate())
+	assert.NoError(t, c.Validate())
+	assert.Empty(t, c.Problems())
+}
+
+func TestBindLint(t *testing.T) {
+	c := New("config.go")
+	c.BindCode(3, Kill)
+	c.BindSym("a", Kill).WholeWindow()
+
+	messages := []string{}
+	for _, p := range c.Problems() {
+		messages = append(messages, p.Message)
+	}
+	assert.Equal(t, []string{
+		"invalid keycode 3 in 3",
+		"a is not a mouse button, --border, --whole-window and --exclude-titlebar have no effect",
+	}, messages)
+}
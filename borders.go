@@ -11,5 +11,10 @@ const (
 )
 
 func (c *Config) HideEdgeBorders(b BorderType) {
+	switch b {
+	case None, Vertical, Horizontal, Both, Smart:
+	default:
+		c.problem(callerSource(), "unknown hide_edge_borders value %q", b)
+	}
 	c.raw("hide_edge_borders " + string(b))
 }
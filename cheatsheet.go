@@ -0,0 +1,328 @@
+package i3config
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// CheatSheetEntry is one key binding as shown in a cheat sheet. Keys has a
+// single combo for bindings and two for chords.
+type CheatSheetEntry struct {
+	Mode        string
+	Keys        []KeyCombo
+	Description string
+	Source      Source
+
+	keycode bool
+	// code is the keycode combo the last key is bound by when it is
+	// translated from a keysym with a keymap
+	code     *KeyCombo
+	commands []*Command
+	// chord is the leading key of a chord that has not been applied yet
+	chord string
+}
+
+// KeyString returns the keys as typed, chords are separated by a space.
+func (e *CheatSheetEntry) KeyString() string {
+	parts := make([]string, len(e.Keys))
+	for i, k := range e.Keys {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+type CheatSheet []*CheatSheetEntry
+
+// CheatSheet lists every binding in the config, its modes and its chords.
+// Variables are resolved where possible and bindings without a description
+// are described by their commands.
+func (c *Config) CheatSheet() CheatSheet {
+	return c.cheatSheet(c.variables(), "default")
+}
+
+func (c *Config) cheatSheet(vars map[string]string, mode string) CheatSheet {
+	sheet := CheatSheet{}
+	resolve := func(k KeyCombo) KeyCombo {
+		if r, err := k.Resolve(vars); err == nil {
+			return r
+		}
+		return k
+	}
+	add := func(keys []KeyCombo, description string, commands []*Command, source Source, b *Bind) {
+		if description == "" {
+			description = describeCommands(commands)
+		}
+		resolved := make([]KeyCombo, len(keys))
+		for i, k := range keys {
+			resolved[i] = resolve(k)
+		}
+		entry := &CheatSheetEntry{
+			Mode:        mode,
+			Keys:        resolved,
+			Description: description,
+			Source:      source,
+			keycode:     b.bindType == "bindcode",
+			commands:    commands,
+		}
+		last := keys[len(keys)-1]
+		if b.wantsCode(last) {
+			if code, err := b.keycode(last); err == nil {
+				code = resolve(code)
+				entry.code = &code
+			}
+		}
+		sheet = append(sheet, entry)
+	}
+
+	modes := CheatSheet{}
+	for _, l := range c.lines {
+		switch g := l.generator.(type) {
+		case *Bind:
+			for _, keys := range g.combos() {
+				add([]KeyCombo{keys}, g.description, g.commands, l.source, g)
+			}
+		case *ModeType:
+			modes = append(modes, g.config.cheatSheet(vars, g.name)...)
+		}
+	}
+	for _, key1 := range c.chords.keys() {
+		for _, bc := range c.chords[key1] {
+			// chords are bound like BindSym when they are applied
+			b := newBind("bindsym", bc.keys, bc.commands)
+			b.translation = c.translation
+			add([]KeyCombo{parseKeyCombo(key1), bc.keys}, bc.description, bc.commands, bc.source, b)
+			sheet[len(sheet)-1].chord = key1
+		}
+	}
+	return append(sheet, modes...)
+}
+
+func describeCommands(commands []*Command) string {
+	strs := make([]string, len(commands))
+	for i, cmd := range commands {
+		strs[i] = cmd.String()
+	}
+	return strings.Join(strs, "; ")
+}
+
+// modes returns the names of the modes in the order they first appear.
+func (s CheatSheet) modes() []string {
+	modes := []string{}
+	seen := map[string]bool{}
+	for _, e := range s {
+		if !seen[e.Mode] {
+			seen[e.Mode] = true
+			modes = append(modes, e.Mode)
+		}
+	}
+	return modes
+}
+
+func (s CheatSheet) inMode(mode string) CheatSheet {
+	entries := CheatSheet{}
+	for _, e := range s {
+		if e.Mode == mode {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func markdownCell(str string) string {
+	return strings.ReplaceAll(str, "|", `\|`)
+}
+
+// Markdown returns a table of bindings per mode.
+func (s CheatSheet) Markdown() string {
+	sections := []string{}
+	for _, mode := range s.modes() {
+		rows := []string{
+			"## " + mode,
+			"",
+			"| Keys | Description |",
+			"| --- | --- |",
+		}
+		for _, e := range s.inMode(mode) {
+			rows = append(rows, fmt.Sprintf("| `%s` | %s |", markdownCell(e.KeyString()), markdownCell(e.Description)))
+		}
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+var cheatSheetHTML = template.Must(template.New("cheatsheet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>i3 key bindings</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+kbd { font-family: monospace; background: #eee; border-radius: 3px; padding: 0 0.3em; }
+</style>
+</head>
+<body>
+<h1>i3 key bindings</h1>
+{{- range .}}
+<h2>{{.Mode}}</h2>
+<table>
+<tr><th>Keys</th><th>Description</th></tr>
+{{- range .Entries}}
+<tr><td><kbd>{{.KeyString}}</kbd></td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML returns a standalone page with a table of bindings per mode.
+func (s CheatSheet) HTML() string {
+	type section struct {
+		Mode    string
+		Entries CheatSheet
+	}
+	sections := []section{}
+	for _, mode := range s.modes() {
+		sections = append(sections, section{Mode: mode, Entries: s.inMode(mode)})
+	}
+	b := &bytes.Buffer{}
+	err := cheatSheetHTML.Execute(b, sections)
+	if err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
+type keyboardKey struct {
+	keysym string
+	label  string
+	width  float64
+}
+
+func keyCap(keysym, label string, width float64) keyboardKey {
+	return keyboardKey{keysym: keysym, label: label, width: width}
+}
+
+func keyRow(keysyms string) []keyboardKey {
+	row := []keyboardKey{}
+	for _, sym := range strings.Fields(keysyms) {
+		row = append(row, keyCap(sym, sym, 1))
+	}
+	return row
+}
+
+func keyRows(rows ...[]keyboardKey) []keyboardKey {
+	all := []keyboardKey{}
+	for _, r := range rows {
+		all = append(all, r...)
+	}
+	return all
+}
+
+// keyboard is an ANSI keyboard labelled with the keysyms of the us layout.
+var keyboard = [][]keyboardKey{
+	keyRows([]keyboardKey{keyCap("Escape", "Esc", 1.5)}, keyRow("F1 F2 F3 F4 F5 F6 F7 F8 F9 F10 F11 F12")),
+	keyRows(
+		[]keyboardKey{keyCap("grave", "`", 1)},
+		keyRow("1 2 3 4 5 6 7 8 9 0"),
+		[]keyboardKey{keyCap("minus", "-", 1), keyCap("equal", "=", 1), keyCap("BackSpace", "Bksp", 2)},
+	),
+	keyRows(
+		[]keyboardKey{keyCap("Tab", "Tab", 1.5)},
+		keyRow("q w e r t y u i o p"),
+		[]keyboardKey{keyCap("bracketleft", "[", 1), keyCap("bracketright", "]", 1), keyCap("backslash", `\`, 1.5)},
+	),
+	keyRows(
+		[]keyboardKey{keyCap("Caps_Lock", "Caps", 1.75)},
+		keyRow("a s d f g h j k l"),
+		[]keyboardKey{keyCap("semicolon", ";", 1), keyCap("apostrophe", "'", 1), keyCap("Return", "Enter", 2.25)},
+	),
+	keyRows(
+		[]keyboardKey{keyCap("Shift_L", "Shift", 2.25)},
+		keyRow("z x c v b n m"),
+		[]keyboardKey{keyCap("comma", ",", 1), keyCap("period", ".", 1), keyCap("slash", "/", 1), keyCap("Shift_R", "Shift", 2.75)},
+	),
+	{keyCap("space", "Space", 6.25), keyCap("Left", "←", 1), keyCap("Down", "↓", 1), keyCap("Up", "↑", 1), keyCap("Right", "→", 1)},
+}
+
+const (
+	keyUnit   = 40.0
+	keyMargin = 4.0
+)
+
+// layers groups the bindings of each mode by their modifiers. Keycode and
+// mouse bindings and chord second keys are not on the keyboard.
+func (s CheatSheet) layers() ([]string, map[string]map[string]string) {
+	names := []string{}
+	layers := map[string]map[string]string{}
+	for _, e := range s {
+		if e.keycode || e.Keys[0].IsButton() {
+			continue
+		}
+		keys := e.Keys[0].Normalize()
+		mods := KeyCombo{Modifiers: keys.Modifiers}.String()
+		name := e.Mode + ": " + strings.TrimSuffix(mods, "+")
+		if mods == "" {
+			name = e.Mode + ": no modifiers"
+		}
+		if _, ok := layers[name]; !ok {
+			names = append(names, name)
+			layers[name] = map[string]string{}
+		}
+		description := e.Description
+		if len(e.Keys) > 1 {
+			description = "chord " + e.KeyString() + ": " + description
+		}
+		if d, ok := layers[name][keys.Key]; ok {
+			description = d + "\n" + description
+		}
+		layers[name][keys.Key] = description
+	}
+	return names, layers
+}
+
+// SVG returns a keyboard diagram per mode and modifier layer with the bound
+// keys highlighted. Hovering a key shows its description.
+func (s CheatSheet) SVG() string {
+	names, layers := s.layers()
+
+	rowWidth := 0.0
+	for _, key := range keyboard[1] {
+		rowWidth += key.width
+	}
+	width := rowWidth*keyUnit + keyMargin
+	layerHeight := float64(len(keyboard))*keyUnit + 40
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" font-family="sans-serif" font-size="12">`+"\n", width, layerHeight*float64(len(names)))
+	for i, name := range names {
+		top := layerHeight * float64(i)
+		fmt.Fprintf(b, `<text x="%g" y="%g" font-size="16">%s</text>`+"\n", keyMargin, top+20, template.HTMLEscapeString(name))
+		for row, keys := range keyboard {
+			x := keyMargin
+			y := top + 30 + float64(row)*keyUnit
+			for _, key := range keys {
+				w := key.width*keyUnit - keyMargin
+				description, bound := layers[name][key.keysym]
+				fill := "#eeeeee"
+				if bound {
+					fill = "#88c0d0"
+				}
+				b.WriteString("<g>")
+				if bound {
+					fmt.Fprintf(b, "<title>%s</title>", template.HTMLEscapeString(description))
+				}
+				fmt.Fprintf(b, `<rect x="%g" y="%g" width="%g" height="%g" rx="4" fill="%s" stroke="#888888"/>`, x, y, w, keyUnit-keyMargin, fill)
+				fmt.Fprintf(b, `<text x="%g" y="%g" text-anchor="middle">%s</text>`, x+w/2, y+keyUnit/2+2, template.HTMLEscapeString(key.label))
+				b.WriteString("</g>\n")
+				x += key.width * keyUnit
+			}
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package i3config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cheatSheetConfig() *Config {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindSym("$mod+q", Kill).Describe("Close | kill the window")
+	c.BindSym("$mod+Shift+Return", Exec("alacritty"))
+	c.BindCode(38, FocusLeft)
+	c.BindChord("$mod+o", "f", Exec("firefox")).Describe("Browser")
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("h", ResizeShrink(Width, 10)).Describe("Narrower")
+	})
+	return c
+}
+
+func TestCheatSheetMarkdown(t *testing.T) {
+	assert.Equal(t, "## default\n"+
+		"\n"+
+		"| Keys | Description |\n"+
+		"| --- | --- |\n"+
+		"| `Mod4+q` | Close \\| kill the window |\n"+
+		"| `Shift+Mod4+Return` | exec \"alacritty\" |\n"+
+		"| `38` | focus left |\n"+
+		"| `Mod4+o f` | Browser |\n"+
+		"\n"+
+		"## resize\n"+
+		"\n"+
+		"| Keys | Description |\n"+
+		"| --- | --- |\n"+
+		"| `h` | Narrower |\n", cheatSheetConfig().CheatSheet().Markdown())
+}
+
+func TestCheatSheetHTML(t *testing.T) {
+	html := cheatSheetConfig().CheatSheet().HTML()
+	assert.Contains(t, html, "<h2>resize</h2>")
+	assert.Contains(t, html, "<tr><td><kbd>Mod4&#43;o f</kbd></td><td>Browser</td></tr>")
+	assert.Contains(t, html, "<td>exec &#34;alacritty&#34;</td>")
+}
+
+func TestCheatSheetSVG(t *testing.T) {
+	svg := cheatSheetConfig().CheatSheet().SVG()
+	for _, layer := range []string{"default: Mod4", "default: Shift+Mod4", "resize: no modifiers"} {
+		assert.Contains(t, svg, ">"+layer+"</text>")
+	}
+	assert.Equal(t, 3, strings.Count(svg, `font-size="16"`))
+	assert.Contains(t, svg, "<title>Close | kill the window</title>")
+	assert.Contains(t, svg, "<title>chord Mod4+o f: Browser</title>")
+	assert.Equal(t, 4, strings.Count(svg, `fill="#88c0d0"`))
+}
+
+func TestChordDescription(t *testing.T) {
+	c := New("config.go")
+	c.BindChord("Mod4+o", "f", Exec("firefox")).Describe("Browser")
+	c.applyChords()
+
+	sheet := c.CheatSheet()
+	assert.Equal(t, "Chord: Mod4+o", sheet[1].Mode)
+	assert.Equal(t, "Browser", sheet[1].Description)
+}
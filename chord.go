@@ -1,35 +1,77 @@
 package i3config
 
+import "sort"
+
 type Chords map[string][]*BoundCommand
 type BoundCommand struct {
-	keys     string
-	commands []*Command
+	keys        KeyCombo
+	commands    []*Command
+	description string
+	source      Source
 }
 
-func (c *Config) BindChord(key1 string, key2 string, commands ...*Command) {
-
+func (c *Config) BindChord(key1 string, key2 string, commands ...*Command) *BoundCommand {
+	source := callerSource()
+	keys1 := parseKeyCombo(key1)
+	key1 = keys1.String()
 	chords, ok := c.chords[key1]
 	if !ok {
 		chords = []*BoundCommand{}
+		if err := keys1.checkKeysym(); err != nil {
+			c.problem(source, "%v", err)
+		}
 	}
-	c.chords[key1] = append(chords, &BoundCommand{
-		keys:     key2,
+	bc := &BoundCommand{
+		keys:     parseKeyCombo(key2),
 		commands: commands,
-	})
+		source:   source,
+	}
+	if err := bc.keys.checkKeysym(); err != nil {
+		c.problem(source, "%v", err)
+	}
+	c.chords[key1] = append(chords, bc)
+	return bc
+}
+
+// Describe sets the text shown for the chord in cheat sheets.
+func (bc *BoundCommand) Describe(description string) *BoundCommand {
+	bc.description = description
+	return bc
 }
 
+// keys returns the leading keys of the chords sorted, anything that walks the
+// chords uses it so problems and output do not depend on map order.
+func (ch Chords) keys() []string {
+	keys := make([]string, 0, len(ch))
+	for key1 := range ch {
+		keys = append(keys, key1)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// apply turns the chords into a mode per leading key, in the order of the
+// keys so the output is the same every time. The generated lines keep the
+// source of the BindChord call that produced them.
 func (ch Chords) apply(c *Config) {
-	for key1, commands := range ch {
+	for _, key1 := range ch.keys() {
+		commands := ch[key1]
 		chordName := "Chord: " + key1
-		c.BindSym(key1, Mode(chordName))
-		c.Mode(chordName, func(sub *Config) {
+		source := commands[0].source
+		c.bind("bindsym", parseKeyCombo(key1), []*Command{Mode(chordName)}, source)
+		c.addLine(c.newMode(chordName, func(sub *Config) {
 			for _, cmd := range commands {
-				sub.BindSym(cmd.keys, append(
+				sub.bind("bindsym", cmd.keys, append(
 					[]*Command{Mode("default")},
 					cmd.commands...,
-				)...)
+				), cmd.source).Describe(cmd.description)
 			}
-			sub.BindSym("Escape", Mode("default"))
-		})
+			sub.bind("bindsym", KeyCombo{Modifiers: []Modifier{}, Key: "Escape"}, []*Command{Mode("default")}, source)
+		}), source)
 	}
 }
+
+func (c *Config) applyChords() {
+	c.chords.apply(c)
+	c.chords = Chords{}
+}
@@ -2,30 +2,244 @@ package i3config
 
 import (
 	"fmt"
-	"os"
+	"math"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
+//go:generate go run ./internal/colorgen colornames.go /usr/share/X11/rgb.txt
+
+// Color is a color written as #RRGGBB, or #RRGGBBAA with an alpha channel
+// where i3bar and i3-gaps accept it.
 type Color string
 
 func HexColor(code string) Color {
 	return Color("#" + code)
 }
 
-func (c Color) Generate() string {
+// ParseColor accepts hex colors with or without the leading #, in the short
+// #RGB form and with alpha, and X11 color names like "steel blue". X11 names
+// differ from CSS for a few colors like gray and green.
+func ParseColor(str string) (Color, error) {
+	if c, ok := namedColors[strings.ToLower(strings.ReplaceAll(str, " ", ""))]; ok {
+		return c, nil
+	}
+	hex := strings.TrimPrefix(str, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	c := Color("#" + strings.ToLower(hex))
 	if !c.Valid() {
-		fmt.Printf("invalid color \"%s\"\n", c)
-		os.Exit(1)
+		return "", fmt.Errorf("invalid color %q", str)
+	}
+	return c, nil
+}
+
+func RGB(r, g, b uint8) Color {
+	return RGBA(r, g, b, 255)
+}
+
+// RGBA returns a color with alpha, opaque colors are written without it.
+func RGBA(r, g, b, a uint8) Color {
+	if a == 255 {
+		return Color(fmt.Sprintf("#%02x%02x%02x", r, g, b))
 	}
+	return Color(fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, a))
+}
+
+// HSL returns the opaque color with hue h in degrees and saturation s and
+// lightness l from 0 to 1.
+func HSL(h, s, l float64) Color {
+	return hsla(h, s, l, 1)
+}
+
+func hsla(h, s, l, a float64) Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s, l = clamp(s), clamp(l)
+
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return RGBA(channel(r+m), channel(g+m), channel(b+m), channel(a))
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Round(clamp(v) * 255))
+}
+
+// Generate writes the color as is, invalid colors are reported by Validate.
+func (c Color) Generate() string {
 	return string(c)
 }
 
-var colorRegExp = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+// checkColor reports invalid colors and variables that are not set in vars.
+func checkColor(problems []string, vars map[string]string, c Color, name string) []string {
+	if isVariable(string(c)) {
+		if _, ok := vars[string(c)]; !ok {
+			return append(problems, fmt.Sprintf("undefined variable %s for %s", c, name))
+		}
+		return problems
+	}
+	if !c.Valid() {
+		return append(problems, fmt.Sprintf("invalid color %q for %s", c, name))
+	}
+	return problems
+}
+
+var colorRegExp = regexp.MustCompile("^#[0-9a-fA-F]{6}([0-9a-fA-F]{2})?$")
 
 func (c Color) Valid() bool {
 	return colorRegExp.Match([]byte(c))
 }
 
+// RGBA returns the channels of the color, invalid colors are transparent
+// black.
+func (c Color) RGBA() (r, g, b, a uint8) {
+	if !c.Valid() {
+		return 0, 0, 0, 0
+	}
+	hex := string(c[1:])
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, _ := strconv.ParseUint(hex, 16, 32)
+	return uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)
+}
+
+// Alpha returns the opacity of the color from 0 to 1.
+func (c Color) Alpha() float64 {
+	_, _, _, a := c.RGBA()
+	return float64(a) / 255
+}
+
+// HSL returns the hue in degrees and the saturation and lightness from 0 to 1.
+func (c Color) HSL() (h, s, l float64) {
+	r8, g8, b8, _ := c.RGBA()
+	r, g, b := float64(r8)/255, float64(g8)/255, float64(b8)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	s = d / (1 - math.Abs(2*l-1))
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG,
+// from 0 for black to 1 for white.
+func (c Color) Luminance() float64 {
+	r, g, b, _ := c.RGBA()
+	linear := func(v uint8) float64 {
+		x := float64(v) / 255
+		if x <= 0.03928 {
+			return x / 12.92
+		}
+		return math.Pow((x+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// Contrast returns the WCAG contrast ratio between the colors, from 1 to 21.
+// Text should have a ratio of at least 4.5 against its background.
+func (c Color) Contrast(other Color) float64 {
+	l1, l2 := c.Luminance(), other.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// The functions below derive new colors and keep the alpha channel. An
+// invalid color is returned unchanged so Generate can report it.
+
+// Lighten raises the lightness by amount, from 0 to 1.
+func (c Color) Lighten(amount float64) Color {
+	if !c.Valid() {
+		return c
+	}
+	h, s, l := c.HSL()
+	return hsla(h, s, l+amount, c.Alpha())
+}
+
+// Darken lowers the lightness by amount, from 0 to 1.
+func (c Color) Darken(amount float64) Color {
+	return c.Lighten(-amount)
+}
+
+// Saturate raises the saturation by amount, from 0 to 1. A negative amount
+// desaturates.
+func (c Color) Saturate(amount float64) Color {
+	if !c.Valid() {
+		return c
+	}
+	h, s, l := c.HSL()
+	return hsla(h, s+amount, l, c.Alpha())
+}
+
+// Mix blends the color with other, weight is the share of other from 0 to 1.
+func (c Color) Mix(other Color, weight float64) Color {
+	if !c.Valid() {
+		return c
+	}
+	if !other.Valid() {
+		return other
+	}
+	weight = clamp(weight)
+	r1, g1, b1, a1 := c.RGBA()
+	r2, g2, b2, a2 := other.RGBA()
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x)*(1-weight) + float64(y)*weight))
+	}
+	return RGBA(mix(r1, r2), mix(g1, g2), mix(b1, b2), mix(a1, a2))
+}
+
+// WithAlpha returns the color with opacity alpha, from 0 to 1.
+func (c Color) WithAlpha(alpha float64) Color {
+	if !c.Valid() {
+		return c
+	}
+	r, g, b, _ := c.RGBA()
+	return RGBA(r, g, b, channel(alpha))
+}
+
 type ColorClass struct {
 	Border      Color
 	Background  Color
@@ -44,6 +258,16 @@ func ConstantColorClass(color Color) ColorClass {
 	}
 }
 
+func (c *ColorClass) check(name string, vars map[string]string) []string {
+	problems := []string{}
+	problems = checkColor(problems, vars, c.Border, name+" border")
+	problems = checkColor(problems, vars, c.Background, name+" background")
+	problems = checkColor(problems, vars, c.Text, name+" text")
+	problems = checkColor(problems, vars, c.Indicator, name+" indicator")
+	problems = checkColor(problems, vars, c.ChildBorder, name+" child_border")
+	return problems
+}
+
 func (c *ColorClass) Generate() string {
 	return fmt.Sprintf("%s %s %s %s %s",
 		c.Border.Generate(),
@@ -63,6 +287,16 @@ type ColorConfig struct {
 	Background      Color
 }
 
+func (c *ColorConfig) check(vars map[string]string) []string {
+	problems := []string{}
+	problems = append(problems, c.Focused.check("client.focused", vars)...)
+	problems = append(problems, c.FocusedInactive.check("client.focused_inactive", vars)...)
+	problems = append(problems, c.Unfocused.check("client.unfocused", vars)...)
+	problems = append(problems, c.Urgent.check("client.urgent", vars)...)
+	problems = append(problems, c.Placeholder.check("client.placeholder", vars)...)
+	return checkColor(problems, vars, c.Background, "client.background")
+}
+
 func (c *ColorConfig) Generate() string {
 	return fmt.Sprintf(
 		"client.focused " + c.Focused.Generate() + "\n" +
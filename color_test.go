@@ -0,0 +1,120 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	testCases := []struct {
+		str   string
+		color Color
+		err   string
+	}{
+		{"#2e3440", "#2e3440", ""},
+		{"2E3440", "#2e3440", ""},
+		{"#2e344080", "#2e344080", ""},
+		{"#fa0", "#ffaa00", ""},
+		{"steel blue", "#4682b4", ""},
+		{"SteelBlue", "#4682b4", ""},
+		{"#2e344", "", `invalid color "#2e344"`},
+		{"nord", "", `invalid color "nord"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.str, func(t *testing.T) {
+			c, err := ParseColor(tc.str)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.color, c)
+		})
+	}
+}
+
+func TestColorHSL(t *testing.T) {
+	testCases := []struct {
+		color   Color
+		h, s, l float64
+	}{
+		{"#000000", 0, 0, 0},
+		{"#ffffff", 0, 0, 1},
+		{"#ff0000", 0, 1, 0.5},
+		{"#00ff00", 120, 1, 0.5},
+		{"#0000ff", 240, 1, 0.5},
+		{"#bf616a", 354.3, 0.423, 0.565},
+		{"#88c0d0", 193.3, 0.434, 0.675},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.color), func(t *testing.T) {
+			h, s, l := tc.color.HSL()
+			assert.InDelta(t, tc.h, h, 0.1)
+			assert.InDelta(t, tc.s, s, 0.001)
+			assert.InDelta(t, tc.l, l, 0.001)
+			assert.Equal(t, tc.color, HSL(h, s, l))
+		})
+	}
+}
+
+func TestColorRGBA(t *testing.T) {
+	r, g, b, a := Color("#2e344080").RGBA()
+	assert.Equal(t, []uint8{0x2e, 0x34, 0x40, 0x80}, []uint8{r, g, b, a})
+	r, g, b, a = Color("#2e3440").RGBA()
+	assert.Equal(t, []uint8{0x2e, 0x34, 0x40, 0xff}, []uint8{r, g, b, a})
+	assert.Equal(t, Color("#2e3440"), RGB(0x2e, 0x34, 0x40))
+	assert.Equal(t, Color("#2e344000"), RGBA(0x2e, 0x34, 0x40, 0))
+}
+
+func TestColorManipulation(t *testing.T) {
+	nord0 := HexColor("2e3440")
+	testCases := []struct {
+		name     string
+		color    Color
+		expected Color
+	}{
+		{"lighten", nord0.Lighten(0.1), "#434c5e"},
+		{"darken", HexColor("ffffff").Darken(0.5), "#808080"},
+		{"darken keeps alpha", HexColor("ff000080").Darken(0.25), "#80000080"},
+		{"lighten clamps", nord0.Lighten(2), "#ffffff"},
+		{"saturate", HexColor("bf8080").Saturate(0.25), "#d76868"},
+		{"desaturate", HexColor("ff0000").Saturate(-1), "#808080"},
+		{"mix", HexColor("000000").Mix(HexColor("ffffff"), 0.5), "#808080"},
+		{"mix weight", HexColor("ff0000").Mix(HexColor("0000ff"), 0.25), "#bf0040"},
+		{"mix alpha", HexColor("ff000000").Mix(HexColor("ff0000"), 0.5), "#ff000080"},
+		{"with alpha", nord0.WithAlpha(0.5), "#2e344080"},
+		{"opaque", HexColor("2e344080").WithAlpha(1), "#2e3440"},
+		{"invalid", Color("nord").Lighten(0.5), "nord"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.color)
+		})
+	}
+}
+
+func TestColorValid(t *testing.T) {
+	assert.True(t, Color("#2e3440").Valid())
+	assert.True(t, Color("#2e3440ff").Valid())
+	assert.False(t, Color("#2e3440f").Valid())
+	assert.False(t, Color("2e3440").Valid())
+}
+
+func TestColorContrast(t *testing.T) {
+	testCases := []struct {
+		a, b     Color
+		contrast float64
+	}{
+		{a: "#ffffff", b: "#000000", contrast: 21},
+		{a: "#000000", b: "#ffffff", contrast: 21},
+		{a: "#777777", b: "#777777", contrast: 1},
+		{a: "#2e3440", b: "#d8dee9", contrast: 9.25},
+		{a: "#81a1c1", b: "#2e3440", contrast: 4.64},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.a)+" "+string(tc.b), func(t *testing.T) {
+			assert.InDelta(t, tc.contrast, tc.a.Contrast(tc.b), 0.01)
+		})
+	}
+}
@@ -2,24 +2,67 @@ package i3config
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// Command is a single i3 command. Commands are immutable, methods that change
+// a command return a modified copy so shared values like FocusLeft are safe
+// to reuse.
 type Command struct {
-	name   string
-	prefix string
-	value  string
+	criteria *Criteria
+	kind     string
+	options  []string
+	args     []CommandArg
+	// err is why NewCommand could not parse the command, Validate reports it
+	err error
 }
 
-func NewCommand(name, value string) *Command {
+// CommandArg is one argument of a command. Quoted arguments are written as
+// double quoted strings.
+type CommandArg struct {
+	Value  string
+	Quoted bool
+}
+
+func newCommand(kind string, args ...CommandArg) *Command {
 	return &Command{
-		name:  name,
-		value: value,
+		kind:    kind,
+		options: []string{},
+		args:    args,
+	}
+}
+
+func words(strs ...string) []CommandArg {
+	args := make([]CommandArg, len(strs))
+	for i, str := range strs {
+		args[i] = CommandArg{Value: str}
+	}
+	return args
+}
+
+func quoted(str string) CommandArg {
+	return CommandArg{Value: str, Quoted: true}
+}
+
+// NewCommand parses value as the arguments of the command name. If it can
+// not be parsed the words of value are used as is and Validate reports the
+// error.
+func NewCommand(name, value string) *Command {
+	src := strings.TrimSpace(name + " " + value)
+	cmds, err := ParseCommand(src)
+	if err == nil && len(cmds) > 1 {
+		err = fmt.Errorf("%q is %d commands", src, len(cmds))
+	}
+	if err != nil || len(cmds) != 1 {
+		cmd := newCommand(name, words(strings.Fields(value)...)...)
+		cmd.err = err
+		return cmd
 	}
+	return cmds[0]
 }
 
 var (
@@ -57,28 +100,27 @@ var (
 var funcKey = 0
 
 func Exec(cmd string) *Command {
-	return NewCommand("exec", escapeString(cmd))
+	return newCommand("exec", quoted(cmd))
 }
 
 func Mode(name string) *Command {
-	return NewCommand("mode", escapeString(name))
+	return newCommand("mode", quoted(name))
 }
 
 func Workspace(name string) *Command {
-	return NewCommand("workspace", escapeString(name))
+	return newCommand("workspace", quoted(name))
 }
 
 func (c *Config) WorkspaceOutput(name string, outputs ...string) {
-	cmd := NewCommand("workspace", escapeString(name)+" output "+strings.Join(outputs, " "))
-	c.AddLine(cmd)
+	c.raw("workspace " + quoteConfig(name) + " output " + strings.Join(outputs, " "))
 }
 
 func MoveContainer(name string) *Command {
-	return NewCommand("move", "container to workspace "+escapeString(name))
+	return newCommand("move", append(words("container", "to", "workspace"), quoted(name))...)
 }
 
 func Border(size int) *Command {
-	return NewCommand("border", fmt.Sprintf("pixel %d", size))
+	return newCommand("border", words("pixel", fmt.Sprint(size))...)
 }
 
 type Direction string
@@ -92,12 +134,16 @@ const (
 	Height Direction = "height"
 )
 
+func resize(change string, direction Direction, amount int) *Command {
+	return newCommand("resize", words(change, string(direction), fmt.Sprint(amount), "px", "or", fmt.Sprint(amount), "ppt")...)
+}
+
 func ResizeGrow(direction Direction, amount int) *Command {
-	return NewCommand("resize", fmt.Sprintf("grow %s %d px or %d ppt", direction, amount, amount))
+	return resize("grow", direction, amount)
 }
 
 func ResizeShrink(direction Direction, amount int) *Command {
-	return NewCommand("resize", fmt.Sprintf("shrink %s %d px or %d ppt", direction, amount, amount))
+	return resize("shrink", direction, amount)
 }
 
 type Size struct {
@@ -106,17 +152,14 @@ type Size struct {
 }
 
 func ResizeSet(size Size) *Command {
-	cmd := "set"
+	args := []string{"set"}
 	if size.Width != 0 {
-		cmd += fmt.Sprintf(" width %d px", size.Width)
+		args = append(args, "width", fmt.Sprint(size.Width), "px")
 	}
 	if size.Height != 0 {
-		cmd += fmt.Sprintf(" height %d px", size.Height)
-	}
-	return &Command{
-		name:  "resize",
-		value: cmd,
+		args = append(args, "height", fmt.Sprint(size.Height), "px")
 	}
+	return newCommand("resize", words(args...)...)
 }
 
 func (c *Config) ExecFunc(cb func() error) *Command {
@@ -137,12 +180,18 @@ func (c *Config) Path() string {
 	return c.path
 }
 
-func (c *Config) RecompileFunc(configPath string) error {
-	b, err := exec.Command("go", "run", c.path).Output()
+// regenerate runs the Go config again to write configPath and the files added
+// with File and ThemeFile.
+func (c *Config) regenerate(configPath string) error {
+	out, err := exec.Command("go", "run", c.path, configPath).CombinedOutput()
 	if err != nil {
-		return err
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
 	}
-	err = os.WriteFile(configPath, b, 0644)
+	return nil
+}
+
+func (c *Config) RecompileFunc(configPath string) error {
+	err := c.regenerate(configPath)
 	if err != nil {
 		return err
 	}
@@ -159,20 +208,267 @@ func (c *Config) Recompile(configPath string) *Command {
 	})
 }
 
-func (c *Command) Generate() string {
-	src := c.name
-	if c.prefix != "" {
-		src += " " + c.prefix
+func (c *Command) Kind() string {
+	return c.kind
+}
+
+func (c *Command) Options() []string {
+	return append([]string{}, c.options...)
+}
+
+func (c *Command) Args() []string {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.Value
+	}
+	return args
+}
+
+// Criteria returns a copy of the criteria the command applies to, or nil.
+func (c *Command) Criteria() *Criteria {
+	if c.criteria == nil {
+		return nil
+	}
+	criteria := *c.criteria
+	return &criteria
+}
+
+// ExecCommand returns the shell command of an exec or exec_always command.
+func (c *Command) ExecCommand() (string, bool) {
+	if (c.kind != "exec" && c.kind != "exec_always") || len(c.args) == 0 {
+		return "", false
+	}
+	return c.args[0].Value, true
+}
+
+// ModeName returns the mode a mode command switches to.
+func (c *Command) ModeName() (string, bool) {
+	if c.kind != "mode" || len(c.args) == 0 {
+		return "", false
+	}
+	return c.args[0].Value, true
+}
+
+// WorkspaceName returns the workspace a workspace command switches to or a
+// move command moves to, "number 3" is returned as "3". Relative targets like
+// next or back_and_forth have no name.
+func (c *Command) WorkspaceName() (string, bool) {
+	args := c.args
+	switch c.kind {
+	case "workspace":
+	case "move":
+		i := 0
+		for i+1 < len(args) && (args[i].Value != "to" || args[i+1].Value != "workspace") {
+			i++
+		}
+		if i+1 >= len(args) {
+			return "", false
+		}
+		args = args[i+2:]
+	default:
+		return "", false
+	}
+	if len(args) == 0 || (workspaceKeywords[args[0].Value] && !args[0].Quoted && args[0].Value != "number") {
+		return "", false
+	}
+	if args[0].Value == "number" && !args[0].Quoted {
+		args = args[1:]
 	}
-	if c.value != "" {
-		src += " " + c.value
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.Value
 	}
-	return src
+	name := strings.Join(names, " ")
+	return name, name != ""
+}
+
+// ResizeAmount is a resize size in px, ppt or both for "10 px or 10 ppt".
+type ResizeAmount struct {
+	Px  int
+	Ppt int
+}
+
+// ResizeArgs are the arguments of a resize command. Sizes without a unit are
+// in px.
+type ResizeArgs struct {
+	// Change is grow, shrink or set
+	Change string
+	// Direction and Amount are set for grow and shrink
+	Direction Direction
+	Amount    ResizeAmount
+	// Width and Height are set for set
+	Width  ResizeAmount
+	Height ResizeAmount
+}
+
+// Resize returns the arguments of a resize command or why they are invalid.
+func (c *Command) Resize() (ResizeArgs, error) {
+	r := ResizeArgs{}
+	if c.kind != "resize" {
+		return r, fmt.Errorf("%s is not a resize command", c.kind)
+	}
+	args := c.Args()
+	if len(args) == 0 {
+		return r, fmt.Errorf("resize has no arguments")
+	}
+	invalid := fmt.Errorf("invalid resize %s", strings.Join(args, " "))
+	r.Change = args[0]
+	switch r.Change {
+	case "grow", "shrink":
+		if len(args) < 2 || !resizeDirections.Has(Direction(args[1])) {
+			return r, invalid
+		}
+		r.Direction = Direction(args[1])
+		rest := args[2:]
+		for len(rest) > 0 {
+			amount, r2, err := resizeAmount(rest, invalid)
+			if err != nil {
+				return r, err
+			}
+			r.Amount.Px += amount.Px
+			r.Amount.Ppt += amount.Ppt
+			rest = r2
+			if len(rest) > 0 {
+				if rest[0] != "or" {
+					return r, invalid
+				}
+				rest = rest[1:]
+			}
+		}
+	case "set":
+		rest := args[1:]
+		if len(rest) == 0 {
+			return r, fmt.Errorf("resize set needs a width or height")
+		}
+		target := &r.Width
+		for len(rest) > 0 {
+			switch rest[0] {
+			case "width":
+				target, rest = &r.Width, rest[1:]
+			case "height":
+				target, rest = &r.Height, rest[1:]
+			}
+			amount, r2, err := resizeAmount(rest, invalid)
+			if err != nil {
+				return r, err
+			}
+			*target = amount
+			target, rest = &r.Height, r2
+		}
+	default:
+		return r, invalid
+	}
+	return r, nil
+}
+
+// resizeAmount parses a number and an optional px or ppt unit from the start
+// of args.
+func resizeAmount(args []string, invalid error) (ResizeAmount, []string, error) {
+	if len(args) == 0 {
+		return ResizeAmount{}, args, invalid
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return ResizeAmount{}, args, invalid
+	}
+	if n < 0 {
+		return ResizeAmount{}, args, fmt.Errorf("invalid resize size %d", n)
+	}
+	args = args[1:]
+	if len(args) > 0 && args[0] == "ppt" {
+		return ResizeAmount{Ppt: n}, args[1:], nil
+	}
+	if len(args) > 0 && args[0] == "px" {
+		args = args[1:]
+	}
+	return ResizeAmount{Px: n}, args, nil
+}
+
+func (c *Command) clone() *Command {
+	n := *c
+	n.options = append([]string{}, c.options...)
+	n.args = append([]CommandArg{}, c.args...)
+	return &n
+}
+
+// For returns a copy of the command that only applies to matching windows.
+func (c *Command) For(criteria Criteria) *Command {
+	n := c.clone()
+	n.criteria = &criteria
+	return n
+}
+
+func (c *Command) WithKind(kind string) *Command {
+	n := c.clone()
+	n.kind = kind
+	return n
+}
+
+func (c *Command) WithArgs(args ...CommandArg) *Command {
+	n := c.clone()
+	n.args = append([]CommandArg{}, args...)
+	return n
+}
+
+func (c *Command) WithOption(option string) *Command {
+	for _, o := range c.options {
+		if o == option {
+			return c
+		}
+	}
+	n := c.clone()
+	n.options = append(n.options, option)
+	return n
+}
+
+func (c *Command) Equal(other *Command) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if c.kind != other.kind || len(c.options) != len(other.options) || len(c.args) != len(other.args) {
+		return false
+	}
+	if (c.criteria == nil) != (other.criteria == nil) || (c.criteria != nil && *c.criteria != *other.criteria) {
+		return false
+	}
+	for i, o := range c.options {
+		if o != other.options[i] {
+			return false
+		}
+	}
+	for i, a := range c.args {
+		if a.Value != other.args[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the command as sent to i3 over IPC.
+func (c *Command) String() string {
+	parts := []string{}
+	if c.criteria != nil {
+		parts = append(parts, "["+c.criteria.String()+"]")
+	}
+	parts = append(parts, c.kind)
+	parts = append(parts, c.options...)
+	for _, arg := range c.args {
+		if arg.Quoted || arg.Value == "" || strings.ContainsAny(arg.Value, " \t\"\\;,[]") {
+			parts = append(parts, quoteCommand(arg.Value))
+		} else {
+			parts = append(parts, arg.Value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Generate returns the command as written in a config file.
+func (c *Command) Generate() string {
+	return configLine(c.String())
 }
 
 func (c *Command) NoStartupID() *Command {
-	c.prefix = "--no-startup-id"
-	return c
+	return c.WithOption("--no-startup-id")
 }
 
 func (c *Config) OnStartup(cmd *Command) {
@@ -180,6 +476,5 @@ func (c *Config) OnStartup(cmd *Command) {
 }
 
 func (c *Config) AlwaysOnStartup(cmd *Command) {
-	cmd.name = "exec_always"
-	c.OnStartup(cmd)
+	c.OnStartup(cmd.WithKind("exec_always"))
 }
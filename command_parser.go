@@ -0,0 +1,160 @@
+package i3config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var workspaceKeywords = map[string]bool{
+	"next":           true,
+	"prev":           true,
+	"next_on_output": true,
+	"prev_on_output": true,
+	"back_and_forth": true,
+	"number":         true,
+	"to":             true,
+}
+
+// stringArg reports whether the next argument of a command is a string that
+// i3 reads up to the next ; or , rather than a single word.
+func stringArg(kind string, args []CommandArg, next string) bool {
+	last := ""
+	if len(args) > 0 {
+		last = args[len(args)-1].Value
+	}
+	switch kind {
+	case "exec", "exec_always", "nop", "title_format", "append_layout", "mode", "mark", "unmark":
+		return len(args) == 0
+	case "workspace":
+		return (len(args) == 0 && !workspaceKeywords[next]) || last == "number"
+	case "move":
+		return (last == "workspace" && !workspaceKeywords[next]) || last == "number" || last == "mark"
+	case "rename":
+		return last == "to"
+	}
+	return false
+}
+
+// ParseCommand parses a list of i3 commands separated by ; or chained with ,
+// as accepted by i3-msg or the command part of a binding. Criteria in front of
+// a chain apply to every command in the chain.
+func ParseCommand(src string) ([]*Command, error) {
+	cmds := []*Command{}
+	var criteria *Criteria
+	rest := src
+
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return cmds, nil
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			c, r, err := parseCriteria(rest[1:])
+			if err != nil {
+				return nil, err
+			}
+			criteria = c
+			rest = strings.TrimLeft(r, " \t")
+		}
+
+		kind, r, err := parseCommandString(rest, true)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" {
+			return nil, fmt.Errorf("expected command at %q", rest)
+		}
+		rest = r
+
+		cmd := newCommand(kind)
+		if criteria != nil {
+			c := *criteria
+			cmd.criteria = &c
+		}
+		for {
+			rest = strings.TrimLeft(rest, " \t")
+			if rest == "" || rest[0] == ';' || rest[0] == ',' {
+				break
+			}
+			quote := rest[0] == '"'
+			word, _, err := parseCommandString(rest, true)
+			if err != nil {
+				return nil, err
+			}
+
+			if !quote && strings.HasPrefix(word, "--") {
+				_, rest, _ = parseCommandString(rest, true)
+				cmd.options = append(cmd.options, word)
+				continue
+			}
+
+			value, r, err := parseCommandString(rest, !stringArg(kind, cmd.args, word))
+			if err != nil {
+				return nil, err
+			}
+			if !quote {
+				value = strings.TrimRight(value, " \t")
+			}
+			rest = r
+			cmd.args = append(cmd.args, CommandArg{Value: value, Quoted: quote})
+		}
+		cmds = append(cmds, cmd)
+
+		if rest != "" {
+			if rest[0] == ';' {
+				criteria = nil
+			}
+			rest = rest[1:]
+		}
+	}
+}
+
+// parseCriteria reads the criteria after an opening [ and returns the
+// remainder after the closing ].
+func parseCriteria(src string) (*Criteria, string, error) {
+	criteria := &Criteria{}
+	fields := criteriaFields()
+	rest := src
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return nil, "", fmt.Errorf("unterminated criteria")
+		}
+		if rest[0] == ']' {
+			return criteria, rest[1:], nil
+		}
+
+		end := strings.IndexAny(rest, "= \t]")
+		if end == -1 {
+			return nil, "", fmt.Errorf("unterminated criteria")
+		}
+		key := rest[:end]
+		rest = rest[end:]
+
+		field, ok := fields[key]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown criterion %s", key)
+		}
+
+		value := "true"
+		if rest[0] == '=' {
+			v, r, err := parseCommandString(rest[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			value, rest = v, r
+		}
+		reflect.ValueOf(criteria).Elem().FieldByIndex(field.Index).SetString(value)
+	}
+}
+
+func criteriaFields() map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	t := reflect.TypeOf(Criteria{})
+	for i := 0; i < t.NumField(); i++ {
+		fields[t.Field(i).Tag.Get("i3")] = t.Field(i)
+	}
+	return fields
+}
@@ -0,0 +1,206 @@
+package i3config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		src      string
+		expected []*Command
+	}{
+		{
+			src:      "focus left",
+			expected: []*Command{FocusLeft},
+		},
+		{
+			src:      `exec --no-startup-id nm-applet &`,
+			expected: []*Command{Exec("nm-applet &").NoStartupID()},
+		},
+		{
+			src:      `exec "feh \"$(cat ~/wallpaper)\""; mode "default"`,
+			expected: []*Command{Exec(`feh "$(cat ~/wallpaper)"`), Mode("default")},
+		},
+		{
+			src:      "workspace 1: web",
+			expected: []*Command{Workspace("1: web")},
+		},
+		{
+			src:      "move container to workspace number 3",
+			expected: []*Command{newCommand("move", words("container", "to", "workspace", "number", "3")...)},
+		},
+		{
+			src:      "resize grow height 10 px or 10 ppt",
+			expected: []*Command{ResizeGrow(Height, 10)},
+		},
+		{
+			src: `[class="^Firefox$" floating] border pixel 4, floating disable; kill`,
+			expected: []*Command{
+				Border(4).For(Criteria{Class: "^Firefox$", Floating: "true"}),
+				FloatingDisabled.WithArgs(CommandArg{Value: "disable"}).For(Criteria{Class: "^Firefox$", Floating: "true"}),
+				Kill,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			cmds, err := ParseCommand(tc.src)
+			require.NoError(t, err)
+			require.Len(t, cmds, len(tc.expected))
+			for i, cmd := range cmds {
+				assert.True(t, tc.expected[i].Equal(cmd), "expected %s got %s", tc.expected[i], cmd)
+
+				reparsed, err := ParseCommand(cmd.String())
+				require.NoError(t, err)
+				require.Len(t, reparsed, 1)
+				assert.True(t, cmd.Equal(reparsed[0]), "round trip %s got %s", cmd, reparsed[0])
+			}
+		})
+	}
+}
+
+func TestParseCommandErrors(t *testing.T) {
+	for _, src := range []string{`exec "foo`, `[class="x" focus`, `[nope=1] focus`, `; focus`} {
+		t.Run(src, func(t *testing.T) {
+			_, err := ParseCommand(src)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCommandImmutable(t *testing.T) {
+	cmd := Exec("comp")
+	c := New("config.go")
+	c.AlwaysOnStartup(cmd)
+	c.OnStartup(cmd.NoStartupID())
+	c.OnStartup(cmd)
+
+	assert.Equal(t, "exec_always \"comp\"\nexec --no-startup-id \"comp\"\nexec \"comp\"\n", c.Generate())
+	assert.Equal(t, "exec", cmd.Kind())
+	assert.Empty(t, cmd.Options())
+}
+
+func TestCommandAccessors(t *testing.T) {
+	cmd := ResizeShrink(Width, 5)
+	assert.Equal(t, "resize", cmd.Kind())
+	assert.Equal(t, []string{"shrink", "width", "5", "px", "or", "5", "ppt"}, cmd.Args())
+	assert.Nil(t, cmd.Criteria())
+
+	focused := FocusLeft.For(Criteria{ConID: "__focused__"})
+	assert.Equal(t, `[con_id="__focused__"] focus left`, focused.String())
+	assert.Equal(t, "focus left", FocusLeft.String())
+}
+
+func TestCommandTypedAccessors(t *testing.T) {
+	testCases := []struct {
+		src       string
+		workspace string
+		mode      string
+		exec      string
+	}{
+		{src: "workspace 1: web", workspace: "1: web"},
+		{src: "workspace number 3", workspace: "3"},
+		{src: "workspace back_and_forth"},
+		{src: `workspace "next"`, workspace: "next"},
+		{src: "move container to workspace number 3", workspace: "3"},
+		{src: "move window to workspace mail", workspace: "mail"},
+		{src: "move container to workspace prev"},
+		{src: "move left"},
+		{src: `mode "resize"`, mode: "resize"},
+		{src: "exec_always --no-startup-id picom", exec: "picom"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			cmds, err := ParseCommand(tc.src)
+			require.NoError(t, err)
+			require.Len(t, cmds, 1)
+
+			workspace, ok := cmds[0].WorkspaceName()
+			assert.Equal(t, tc.workspace, workspace)
+			assert.Equal(t, tc.workspace != "", ok)
+			mode, ok := cmds[0].ModeName()
+			assert.Equal(t, tc.mode, mode)
+			assert.Equal(t, tc.mode != "", ok)
+			exec, ok := cmds[0].ExecCommand()
+			assert.Equal(t, tc.exec, exec)
+			assert.Equal(t, tc.exec != "", ok)
+		})
+	}
+}
+
+func TestCommandResize(t *testing.T) {
+	testCases := []struct {
+		cmd      *Command
+		expected ResizeArgs
+		err      string
+	}{
+		{
+			cmd:      ResizeGrow(Width, 10),
+			expected: ResizeArgs{Change: "grow", Direction: Width, Amount: ResizeAmount{Px: 10, Ppt: 10}},
+		},
+		{
+			cmd:      NewCommand("resize", "shrink left 5 ppt"),
+			expected: ResizeArgs{Change: "shrink", Direction: Left, Amount: ResizeAmount{Ppt: 5}},
+		},
+		{
+			cmd:      ResizeSet(Size{Width: 640, Height: 480}),
+			expected: ResizeArgs{Change: "set", Width: ResizeAmount{Px: 640}, Height: ResizeAmount{Px: 480}},
+		},
+		{
+			cmd:      NewCommand("resize", "set height 50 ppt"),
+			expected: ResizeArgs{Change: "set", Height: ResizeAmount{Ppt: 50}},
+		},
+		{
+			cmd:      NewCommand("resize", "set 800 600"),
+			expected: ResizeArgs{Change: "set", Width: ResizeAmount{Px: 800}, Height: ResizeAmount{Px: 600}},
+		},
+		{cmd: ResizeGrow("sideways", 10), err: "invalid resize grow sideways 10 px or 10 ppt"},
+		{cmd: NewCommand("resize", "grow up ten px"), err: "invalid resize grow up ten px"},
+		{cmd: ResizeShrink(Up, -5), err: "invalid resize size -5"},
+		{cmd: ResizeSet(Size{}), err: "resize set needs a width or height"},
+		{cmd: FocusLeft, err: "focus is not a resize command"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cmd.String(), func(t *testing.T) {
+			resize, err := tc.cmd.Resize()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resize)
+		})
+	}
+}
+
+func TestNewCommandError(t *testing.T) {
+	cmd := NewCommand("exec", `"foo`)
+	assert.Equal(t, "exec", cmd.Kind())
+	assert.Error(t, cmd.err)
+	assert.NoError(t, FocusLeft.err)
+
+	c := New("config.go")
+	line := currentLine() + 1
+	c.BindSym("Mod4+x", cmd, NewCommand("focus", "left; kill"))
+
+	problems := c.Problems()
+	require.Len(t, problems, 2)
+	assert.Contains(t, problems[0].Message, "invalid command: ")
+	assert.Equal(t, `invalid command: "focus left; kill" is 2 commands`, problems[1].Message)
+	assert.Equal(t, line, problems[0].Source.Line)
+}
+
+func TestRegenerateError(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main\n\nfunc main() { undefined() }\n"), 0644))
+
+	err := New(file).regenerate(filepath.Join(dir, "config"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undefined: undefined")
+}
@@ -0,0 +1,103 @@
+package i3config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// outputOptions change how a config and its modes and bars are generated.
+type outputOptions struct {
+	annotate bool
+	dedupe   bool
+}
+
+type comment string
+
+func (c comment) Generate() string {
+	lines := strings.Split(string(c), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("# "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Comment adds a # comment, each line of text becomes a comment line.
+func (c *Config) Comment(text string) {
+	c.AddLine(comment(text))
+}
+
+// sectionHeader separates a section from the lines before it with a blank
+// line.
+type sectionHeader struct {
+	title    string
+	separate bool
+}
+
+func (s *sectionHeader) Generate() string {
+	src := comment(s.title).Generate()
+	if s.separate {
+		src = "\n" + src
+	}
+	return src
+}
+
+// sectionEnd separates the last line of a section from the next line that
+// does not start a section with a blank line.
+type sectionEnd struct{}
+
+func (sectionEnd) Generate() string {
+	return ""
+}
+
+// Section adds the lines of section under a comment with its title. The lines
+// are part of the config like any other, Section only changes how it reads.
+func (c *Config) Section(title string, section func(c *Config)) {
+	c.AddLine(&sectionHeader{
+		title:    title,
+		separate: len(c.lines) > 0,
+	})
+	section(c)
+	c.AddLine(sectionEnd{})
+}
+
+// AnnotateSources writes the Go file and line that added each line of the
+// config in a comment above it, relative to the directory of the config.
+func (c *Config) AnnotateSources() {
+	c.output.annotate = true
+}
+
+// Dedupe leaves out lines that are the same as an earlier line of the same
+// block, like the for_window rule a helper adds each time it is called.
+// Comments are kept.
+func (c *Config) Dedupe() {
+	c.output.dedupe = true
+}
+
+// isDuplicate reports if the generated line was seen before and records it.
+func isDuplicate(l *line, generated string, seen map[string]bool) bool {
+	switch l.generator.(type) {
+	case comment, *sectionHeader:
+		return false
+	}
+	if seen[generated] {
+		return true
+	}
+	seen[generated] = true
+	return false
+}
+
+// annotation returns the comment with the source of l, or "" for comments.
+func (c *Config) annotation(l *line) string {
+	switch l.generator.(type) {
+	case comment, *sectionHeader:
+		return ""
+	}
+	if l.source.File == "" {
+		return ""
+	}
+	source := l.source
+	if rel, err := filepath.Rel(filepath.Dir(c.path), source.File); err == nil && !strings.HasPrefix(rel, "..") {
+		source.File = rel
+	}
+	return comment(source.String()).Generate() + "\n"
+}
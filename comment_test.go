@@ -0,0 +1,118 @@
+package i3config
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComment(t *testing.T) {
+	c := New("config.go")
+	c.Comment("generated by i3config\ndo not edit")
+	c.Section("Workspaces", func(c *Config) {
+		c.BindSym("Mod4+1", Workspace("1"))
+		c.Comment("")
+	})
+	c.Section("Apps", func(c *Config) {
+		c.BindSym("Mod4+Return", Exec("alacritty"))
+	})
+
+	assert.Equal(t, `# generated by i3config
+# do not edit
+
+# Workspaces
+bindsym Mod4+1 workspace "1"
+#
+
+# Apps
+bindsym Mod4+Return exec "alacritty"
+`, c.Generate())
+}
+
+func TestSectionFirst(t *testing.T) {
+	c := New("config.go")
+	c.Section("Workspaces", func(c *Config) {
+		c.BindSym("Mod4+1", Workspace("1"))
+		c.BindSym("Mod4+1", Workspace("2"))
+	})
+
+	assert.Equal(t, "# Workspaces\nbindsym Mod4+1 workspace \"1\"\nbindsym Mod4+1 workspace \"2\"\n", c.Generate())
+	// lines in a section are checked with the rest of the config
+	assert.Len(t, c.Problems(), 1)
+}
+
+func TestAnnotateSources(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	c := New(file)
+	c.AnnotateSources()
+	c.Comment("keys")
+	setLine := currentLine() + 1
+	c.Set("$mod", "Mod4")
+	modeLine := currentLine() + 1
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+	})
+	escapeLine := modeLine + 1
+
+	assert.Equal(t, fmt.Sprintf(`# keys
+# comment_test.go:%d
+set $mod Mod4
+# comment_test.go:%d
+mode "resize" {
+    # comment_test.go:%d
+    bindsym Escape mode "default"
+    `+`
+}
+`, setLine, modeLine, escapeLine), c.Generate())
+}
+
+func TestDedupe(t *testing.T) {
+	c := New("config.go")
+	c.Dedupe()
+	for i := 0; i < 2; i++ {
+		c.Comment("quake")
+		c.ForWindow(Criteria{Instance: "quake_term"}, FloatingEnabled)
+		c.Mode("resize", func(c *Config) {
+			c.BindSym("Escape", Mode("default"))
+			c.BindSym("Escape", Mode("default"))
+		})
+	}
+
+	assert.Equal(t, `# quake
+for_window [instance="quake_term"] floating enabled
+mode "resize" {
+    bindsym Escape mode "default"
+    `+`
+}
+# quake
+`, c.Generate())
+}
+
+func TestSectionEnd(t *testing.T) {
+	c := New("config.go")
+	c.Section("Workspaces", func(c *Config) {
+		c.BindSym("Mod4+1", Workspace("1"))
+	})
+	c.BindSym("Mod4+q", Kill)
+	c.Mode("resize", func(c *Config) {
+		c.Section("Keys", func(c *Config) {
+			c.BindSym("Escape", Mode("default"))
+		})
+		c.BindSym("Return", Mode("default"))
+	})
+
+	assert.Equal(t, `# Workspaces
+bindsym Mod4+1 workspace "1"
+
+bindsym Mod4+q kill
+mode "resize" {
+    # Keys
+    bindsym Escape mode "default"
+    `+`
+    bindsym Return mode "default"
+    `+`
+}
+`, c.Generate())
+}
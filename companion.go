@@ -0,0 +1,284 @@
+package i3config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// companionFile is a config file of another program that is written with the
+// i3 config.
+type companionFile struct {
+	path   string
+	file   func(t Theme) Generator
+	themed bool
+	source Source
+}
+
+// File writes the output of g to path whenever the config is written by Run
+// or Apply. A leading ~ in path is the home directory.
+func (c *Config) File(path string, g Generator) {
+	if c.subConfig {
+		panic("File must be used from a root config")
+	}
+	c.files = append(c.files, &companionFile{
+		path:   path,
+		file:   func(Theme) Generator { return g },
+		source: callerSource(),
+	})
+}
+
+// ThemeFile is like File for a file generated from the theme of the config,
+// it follows the theme picked by WallpaperTheme or ThemeSchedule.
+func (c *Config) ThemeFile(path string, file func(t Theme) Generator) {
+	if c.subConfig {
+		panic("ThemeFile must be used from a root config")
+	}
+	c.files = append(c.files, &companionFile{
+		path:   path,
+		file:   file,
+		themed: true,
+		source: callerSource(),
+	})
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// writeFiles writes every file added with File and ThemeFile.
+func (c *Config) writeFiles() error {
+	for _, f := range c.files {
+		if f.themed && c.theme == nil {
+			return fmt.Errorf("%s: no theme set", f.path)
+		}
+		theme := Theme{}
+		if c.theme != nil {
+			theme = *c.theme
+		}
+		path, err := expandHome(f.path)
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, []byte(f.file(theme).Generate()), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plainFont removes the pango: prefix i3 uses from a font.
+func plainFont(font string) string {
+	return strings.TrimPrefix(font, "pango:")
+}
+
+// I3statusModule is a block of i3status.conf like `disk "/" { ... }`.
+type I3statusModule struct {
+	Name     string
+	Instance string
+	Options  map[string]string
+}
+
+func (m *I3statusModule) order() string {
+	if m.Instance == "" {
+		return m.Name
+	}
+	return m.Name + " " + m.Instance
+}
+
+// i3statusValue quotes the value unless it is a number or a boolean.
+func i3statusValue(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil || value == "true" || value == "false" {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func i3statusBlock(header string, options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + " = " + i3statusValue(options[key])
+	}
+	return header + " {\n" + indent(strings.Join(lines, "\n")) + "\n}\n"
+}
+
+func (m *I3statusModule) Generate() string {
+	header := m.Name
+	if m.Instance != "" {
+		header += " " + strconv.Quote(m.Instance)
+	}
+	return i3statusBlock(header, m.Options)
+}
+
+// I3statusConfig is an i3status.conf using the foreground for good, the
+// accent for degraded and the urgent color for bad values.
+type I3statusConfig struct {
+	Theme    Theme
+	Interval int
+	Modules  []*I3statusModule
+}
+
+func (s *I3statusConfig) Generate() string {
+	general := map[string]string{
+		"colors":         "true",
+		"color_good":     string(s.Theme.Foreground),
+		"color_degraded": string(s.Theme.Accent),
+		"color_bad":      string(s.Theme.Urgent),
+	}
+	if s.Interval > 0 {
+		general["interval"] = fmt.Sprint(s.Interval)
+	}
+	src := i3statusBlock("general", general) + "\n"
+	for _, m := range s.Modules {
+		src += "order += " + strconv.Quote(m.order()) + "\n"
+	}
+	for _, m := range s.Modules {
+		src += "\n" + m.Generate()
+	}
+	return src
+}
+
+// Dunstrc is a dunstrc with the colors and font of the theme, urgent
+// notifications get the urgent frame.
+type Dunstrc struct {
+	Theme Theme
+}
+
+func (d *Dunstrc) Generate() string {
+	t := d.Theme
+	section := func(name string, lines ...string) string {
+		return "[" + name + "]\n" + indent(strings.Join(lines, "\n")) + "\n"
+	}
+	global := []string{}
+	if t.Font != "" {
+		global = append(global, "font = "+plainFont(t.Font))
+	}
+	global = append(global,
+		fmt.Sprintf("frame_color = %q", t.Accent),
+		"separator_color = frame",
+	)
+	return strings.Join([]string{
+		section("global", global...),
+		section("urgency_low",
+			fmt.Sprintf("background = %q", t.Background),
+			fmt.Sprintf("foreground = %q", t.dim()),
+		),
+		section("urgency_normal",
+			fmt.Sprintf("background = %q", t.Background),
+			fmt.Sprintf("foreground = %q", t.Foreground),
+		),
+		section("urgency_critical",
+			fmt.Sprintf("background = %q", t.Background),
+			fmt.Sprintf("foreground = %q", t.Foreground),
+			fmt.Sprintf("frame_color = %q", t.Urgent),
+		),
+	}, "\n")
+}
+
+// RofiTheme is a rofi theme with the colors and font of the theme, the
+// selected entry is drawn like a focused window.
+type RofiTheme struct {
+	Theme Theme
+}
+
+func (r *RofiTheme) Generate() string {
+	t := r.Theme
+	block := func(name string, lines ...string) string {
+		return name + " {\n" + indent(strings.Join(lines, "\n")) + "\n}\n"
+	}
+	global := []string{
+		"background: " + string(t.Background) + ";",
+		"foreground: " + string(t.Foreground) + ";",
+		"accent: " + string(t.Accent) + ";",
+		"urgent: " + string(t.Urgent) + ";",
+		"inactive: " + string(t.Inactive) + ";",
+		"background-color: @background;",
+		"text-color: @foreground;",
+	}
+	if t.Font != "" {
+		global = append(global, fmt.Sprintf("font: %q;", plainFont(t.Font)))
+	}
+	return strings.Join([]string{
+		block("*", global...),
+		block("window", "border: 2px;", "border-color: @accent;"),
+		block("inputbar", "background-color: @inactive;"),
+		block("element selected", "background-color: @accent;", "text-color: @background;"),
+		block("element urgent", "text-color: @urgent;"),
+		block("element-text", "background-color: inherit;", "text-color: inherit;"),
+	}, "\n")
+}
+
+var ansiColorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// AlacrittyColors is the colors section of an alacritty.toml, it can be
+// imported from the main config.
+type AlacrittyColors struct {
+	Palette *Palette
+}
+
+func (a *AlacrittyColors) Generate() string {
+	p := a.Palette
+	table := func(name string, colors [][2]string) string {
+		src := "[colors." + name + "]\n"
+		for _, c := range colors {
+			src += fmt.Sprintf("%s = %q\n", c[0], c[1])
+		}
+		return src
+	}
+	ansi := func(offset int) [][2]string {
+		colors := make([][2]string, len(ansiColorNames))
+		for i, name := range ansiColorNames {
+			colors[i] = [2]string{name, string(p.Colors[offset+i])}
+		}
+		return colors
+	}
+	return strings.Join([]string{
+		table("primary", [][2]string{
+			{"background", string(p.Background)},
+			{"foreground", string(p.Foreground)},
+		}),
+		table("cursor", [][2]string{
+			{"cursor", string(p.Cursor)},
+			{"text", string(p.Background)},
+		}),
+		table("normal", ansi(0)),
+		table("bright", ansi(8)),
+	}, "\n")
+}
+
+// XresourcesColors writes the palette as X resources for terminals like xterm
+// and urxvt, it can be read back with ParseXresources.
+type XresourcesColors struct {
+	Palette *Palette
+}
+
+func (x *XresourcesColors) Generate() string {
+	p := x.Palette
+	src := "*.background: " + string(p.Background) + "\n" +
+		"*.foreground: " + string(p.Foreground) + "\n" +
+		"*.cursorColor: " + string(p.Cursor) + "\n"
+	for i, c := range p.Colors {
+		src += fmt.Sprintf("*.color%d: %s\n", i, c)
+	}
+	return src
+}
@@ -0,0 +1,175 @@
+package i3config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanionGenerators(t *testing.T) {
+	theme := NordTheme
+	theme.Font = "pango:DejaVu Sans Mono 11"
+	palette, err := LoadXresources("testdata/palettes/nord.Xresources")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		generator Generator
+		src       string
+	}{
+		{name: "i3status", generator: &I3statusConfig{
+			Theme:    theme,
+			Interval: 5,
+			Modules: []*I3statusModule{
+				{Name: "disk", Instance: "/", Options: map[string]string{"format": "%avail"}},
+				{Name: "tztime", Instance: "local", Options: map[string]string{"format": "%Y-%m-%d %H:%M"}},
+			},
+		}, src: `general {
+    color_bad = "#bf616a"
+    color_degraded = "#81a1c1"
+    color_good = "#d8dee9"
+    colors = true
+    interval = 5
+}
+
+order += "disk /"
+order += "tztime local"
+
+disk "/" {
+    format = "%avail"
+}
+
+tztime "local" {
+    format = "%Y-%m-%d %H:%M"
+}
+`},
+		{name: "dunst", generator: &Dunstrc{Theme: theme}, src: `[global]
+    font = DejaVu Sans Mono 11
+    frame_color = "#81a1c1"
+    separator_color = frame
+
+[urgency_low]
+    background = "#2e3440"
+    foreground = "#949aa5"
+
+[urgency_normal]
+    background = "#2e3440"
+    foreground = "#d8dee9"
+
+[urgency_critical]
+    background = "#2e3440"
+    foreground = "#d8dee9"
+    frame_color = "#bf616a"
+`},
+		{name: "rofi", generator: &RofiTheme{Theme: theme}, src: `* {
+    background: #2e3440;
+    foreground: #d8dee9;
+    accent: #81a1c1;
+    urgent: #bf616a;
+    inactive: #3b4252;
+    background-color: @background;
+    text-color: @foreground;
+    font: "DejaVu Sans Mono 11";
+}
+
+window {
+    border: 2px;
+    border-color: @accent;
+}
+
+inputbar {
+    background-color: @inactive;
+}
+
+element selected {
+    background-color: @accent;
+    text-color: @background;
+}
+
+element urgent {
+    text-color: @urgent;
+}
+
+element-text {
+    background-color: inherit;
+    text-color: inherit;
+}
+`},
+		{name: "alacritty", generator: &AlacrittyColors{Palette: palette}, src: `[colors.primary]
+background = "#2e3440"
+foreground = "#d8dee9"
+
+[colors.cursor]
+cursor = "#d8dee9"
+text = "#2e3440"
+
+[colors.normal]
+black = "#3b4252"
+red = "#bf616a"
+green = "#a3be8c"
+yellow = "#ebcb8b"
+blue = "#81a1c1"
+magenta = "#b48ead"
+cyan = "#88c0d0"
+white = "#e5e9f0"
+
+[colors.bright]
+black = "#4c566a"
+red = "#bf616a"
+green = "#a3be8c"
+yellow = "#ebcb8b"
+blue = "#81a1c1"
+magenta = "#b48ead"
+cyan = "#8fbcbb"
+white = "#eceff4"
+`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.src, tc.generator.Generate())
+		})
+	}
+}
+
+func TestXresourcesColors(t *testing.T) {
+	palette, err := LoadXresources("testdata/palettes/nord.Xresources")
+	require.NoError(t, err)
+	parsed, err := ParseXresources((&XresourcesColors{Palette: palette}).Generate())
+	require.NoError(t, err)
+	assert.Equal(t, palette, parsed)
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := New("config.go")
+	c.Theme(NordTheme)
+	c.File(filepath.Join(dir, "static"), fontLine("monospace"))
+	c.ThemeFile(filepath.Join(dir, "rofi", "theme.rasi"), func(t Theme) Generator {
+		return &RofiTheme{Theme: t}
+	})
+
+	assert.NoError(t, c.writeFiles())
+	b, err := os.ReadFile(filepath.Join(dir, "static"))
+	assert.NoError(t, err)
+	assert.Equal(t, "font monospace", string(b))
+	b, err = os.ReadFile(filepath.Join(dir, "rofi", "theme.rasi"))
+	assert.NoError(t, err)
+	assert.Equal(t, (&RofiTheme{Theme: NordTheme}).Generate(), string(b))
+}
+
+func TestThemeFileWithoutTheme(t *testing.T) {
+	c := New("config.go")
+	line := currentLine() + 1
+	c.ThemeFile("~/.config/dunst/dunstrc", func(t Theme) Generator {
+		return &Dunstrc{Theme: t}
+	})
+
+	problems := c.Problems()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "theme file ~/.config/dunst/dunstrc needs a theme", problems[0].Message)
+	assert.Equal(t, line, problems[0].Source.Line)
+	assert.EqualError(t, c.writeFiles(), "~/.config/dunst/dunstrc: no theme set")
+}
@@ -0,0 +1,93 @@
+package i3config
+
+import "fmt"
+
+// contrastLimits are shared by a config and its modes and bars. Text below
+// warn is a warning and below fail an error, 0 turns the check off.
+type contrastLimits struct {
+	warn float64
+	fail float64
+}
+
+// MinContrast warns about text colors with a contrast against their
+// background below ratio. WCAG AA asks for at least 4.5 for text.
+func (c *Config) MinContrast(ratio float64) {
+	c.contrast.warn = ratio
+}
+
+// RequireContrast makes text colors with a contrast against their background
+// below ratio an error.
+func (c *Config) RequireContrast(ratio float64) {
+	c.contrast.fail = ratio
+}
+
+// contrastPair is a text color and the background it is drawn on.
+type contrastPair struct {
+	name       string
+	text       Color
+	background Color
+}
+
+func (c *ColorClass) contrastPairs(name string) []contrastPair {
+	return []contrastPair{{name + " text", c.Text, c.Background}}
+}
+
+func (c *ColorConfig) contrastPairs() []contrastPair {
+	pairs := []contrastPair{}
+	pairs = append(pairs, c.Focused.contrastPairs("client.focused")...)
+	pairs = append(pairs, c.FocusedInactive.contrastPairs("client.focused_inactive")...)
+	pairs = append(pairs, c.Unfocused.contrastPairs("client.unfocused")...)
+	pairs = append(pairs, c.Urgent.contrastPairs("client.urgent")...)
+	return append(pairs, c.Placeholder.contrastPairs("client.placeholder")...)
+}
+
+func (b *BarWorkspaceColor) contrastPairs(name string) []contrastPair {
+	if b == nil {
+		return []contrastPair{}
+	}
+	return []contrastPair{{name + " text", b.Text, b.Background}}
+}
+
+func (b *BarColorConfig) contrastPairs() []contrastPair {
+	pairs := []contrastPair{}
+	if b.StatusLine != "" && b.Background != "" {
+		pairs = append(pairs, contrastPair{"bar statusline", b.StatusLine, b.Background})
+	}
+	pairs = append(pairs, b.FocusedWorkspace.contrastPairs("bar focused_workspace")...)
+	pairs = append(pairs, b.ActiveWorkspace.contrastPairs("bar active_workspace")...)
+	pairs = append(pairs, b.InactiveWorkspace.contrastPairs("bar inactive_workspace")...)
+	return append(pairs, b.UrgentWorkspace.contrastPairs("bar urgent_workspace")...)
+}
+
+// check reports text with too little contrast. Variables are replaced by
+// their value, a resource variable by its fallback. Colors that are still
+// not valid, like an unset variable, are reported by checkColor and skipped.
+func (l *contrastLimits) check(v *validator, pairs []contrastPair, source Source) {
+	for _, p := range pairs {
+		text, background := v.color(p.text), v.color(p.background)
+		if !text.Valid() || !background.Valid() {
+			continue
+		}
+		ratio := text.Contrast(background)
+		message := fmt.Sprintf("low contrast %.2f between %s %s and background %s", ratio, p.name, colorName(p.text, text), colorName(p.background, background))
+		if ratio < l.fail {
+			v.report(source, fmt.Sprintf("%s, at least %g is required", message, l.fail))
+		} else if ratio < l.warn {
+			v.warn(source, fmt.Sprintf("%s, at least %g is recommended", message, l.warn))
+		}
+	}
+}
+
+// color replaces the variables in c with their values.
+func (v *validator) color(c Color) Color {
+	return Color(substituteVariables(string(c), v.vars))
+}
+
+// colorName returns the color as written, followed by its value if it uses a
+// variable.
+func colorName(c, resolved Color) string {
+	if c == resolved {
+		return string(c)
+	}
+	return fmt.Sprintf("%s (%s)", c, resolved)
+}
@@ -0,0 +1,110 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrast(t *testing.T) {
+	colors := &ColorConfig{
+		Focused:         ColorClass{"#81a1c1", "#81a1c1", "#2e3440", "#81a1c1", "#81a1c1"},
+		FocusedInactive: ColorClass{"#3b4252", "#3b4252", "#d8dee9", "#3b4252", "#3b4252"},
+		Unfocused:       ColorClass{"#2e3440", "#2e3440", "#4c566a", "#2e3440", "#2e3440"},
+		Urgent:          ColorClass{"#bf616a", "#bf616a", "$fg", "#bf616a", "#bf616a"},
+		Placeholder:     ColorClass{"#2e3440", "#2e3440", "#d8dee9", "#2e3440", "#2e3440"},
+		Background:      "#2e3440",
+	}
+	barColors := &BarColorConfig{
+		Background: "#2e3440",
+		StatusLine: "#3b4252",
+		FocusedWorkspace: &BarWorkspaceColor{
+			Border:     "#81a1c1",
+			Background: "#81a1c1",
+			Text:       "#2e3440",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		warn     float64
+		fail     float64
+		problems []string
+	}{
+		{name: "off", problems: []string{}},
+		{name: "warn", warn: 4.5, problems: []string{
+			"warning: low contrast 1.69 between client.unfocused text #4c566a and background #2e3440, at least 4.5 is recommended",
+			"warning: low contrast 1.24 between bar statusline #3b4252 and background #2e3440, at least 4.5 is recommended",
+		}},
+		{name: "fail", warn: 4.5, fail: 4.7, problems: []string{
+			"low contrast 4.64 between client.focused text #2e3440 and background #81a1c1, at least 4.7 is required",
+			"low contrast 1.69 between client.unfocused text #4c566a and background #2e3440, at least 4.7 is required",
+			"low contrast 1.24 between bar statusline #3b4252 and background #2e3440, at least 4.7 is required",
+			"low contrast 4.64 between bar focused_workspace text #2e3440 and background #81a1c1, at least 4.7 is required",
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New("config.go")
+			c.SetFromResource("$fg", "i3wm.foreground", "")
+			c.MinContrast(tc.warn)
+			c.RequireContrast(tc.fail)
+			c.Colors(colors)
+			c.Bar(func(bc *BarConfig) {
+				bc.Colors(barColors)
+			})
+
+			problems := []string{}
+			for _, p := range c.Problems() {
+				message := p.Message
+				if p.Warning {
+					message = "warning: " + message
+				}
+				problems = append(problems, message)
+			}
+			assert.Equal(t, tc.problems, problems)
+		})
+	}
+}
+
+func TestContrastTheme(t *testing.T) {
+	c := New("config.go")
+	c.RequireContrast(3.5)
+	c.Theme(SolarizedDarkTheme)
+	line := currentLine() + 1
+	c.Bar(func(bc *BarConfig) {})
+
+	problems := c.Problems()
+	require.Len(t, problems, 4)
+	assert.Equal(t, "low contrast 2.59 between bar inactive_workspace text #4f6a70 and background #002b36, at least 3.5 is required", problems[2].Message)
+	assert.Equal(t, line, problems[2].Source.Line)
+}
+
+func TestContrastVariables(t *testing.T) {
+	c := New("config.go")
+	c.RequireContrast(4.5)
+	c.Set("$bg", "#2e3440")
+	c.Set("$fg", "#3b4252")
+	c.SetFromResource("$urgent", "i3wm.urgent", "#bf616a")
+	c.SetFromResource("$accent", "i3wm.accent", "")
+	line := currentLine() + 1
+	c.Colors(&ColorConfig{
+		Focused:         ColorClass{"$accent", "$accent", "$bg", "$accent", "$accent"},
+		FocusedInactive: ColorClass{"$bg", "$bg", "#d8dee9", "$bg", "$bg"},
+		Unfocused:       ColorClass{"$bg", "$bg", "$fg", "$bg", "$bg"},
+		Urgent:          ColorClass{"$urgent", "$urgent", "#bf616a", "$urgent", "$urgent"},
+		Placeholder:     ColorClass{"$bg", "$bg", "#d8dee9", "$bg", "$bg"},
+		Background:      "$bg",
+	})
+
+	messages := []string{}
+	for _, p := range c.Problems() {
+		messages = append(messages, p.Message)
+		assert.Equal(t, line, p.Source.Line)
+	}
+	assert.Equal(t, []string{
+		"low contrast 1.24 between client.unfocused text $fg (#3b4252) and background $bg (#2e3440), at least 4.5 is required",
+		"low contrast 1.00 between client.urgent text #bf616a and background $urgent (#bf616a), at least 4.5 is required",
+	}, messages)
+}
@@ -10,40 +10,8 @@ import (
 	. "github.com/abibby/i3config"
 )
 
-var (
-	Nord0  = HexColor("2e3440")
-	Nord1  = HexColor("3b4252")
-	Nord2  = HexColor("434c5e")
-	Nord3  = HexColor("4c566a")
-	Nord4  = HexColor("d8dee9")
-	Nord5  = HexColor("e5e9f0")
-	Nord6  = HexColor("eceff4")
-	Nord7  = HexColor("8fbcbb")
-	Nord8  = HexColor("88c0d0")
-	Nord9  = HexColor("81a1c1")
-	Nord10 = HexColor("5e81ac")
-	Nord11 = HexColor("bf616a")
-	Nord12 = HexColor("d08770")
-	Nord13 = HexColor("ebcb8b")
-	Nord14 = HexColor("a3be8c")
-	Nord15 = HexColor("b48ead")
-)
-
-var (
-	Background = Nord0
-	Foreground = Nord4
-
-	Black   = Nord1
-	Red     = Nord11
-	Green   = Nord14
-	Yellow  = Nord13
-	Blue    = Nord9
-	Magenta = Nord15
-	Cyan    = Nord8
-	White   = Nord15
-)
-
 var term = "alacritty"
+var configPath = "/home/adam/.config/i3/config"
 var editor = "code"
 
 // var editor = term + " -e nvim"
@@ -52,7 +20,9 @@ func main() {
 	// "~/.config/i3/config"
 	c := New("/Users/abibby/github.com/abibby/i3config/example/main.go")
 
+	c.Comment("Generated by i3config from example/main.go, edit that instead")
 	c.Set("$mod", "Mod4")
+	c.Dedupe()
 
 	c.Gaps(Gaps{
 		Inner: 10,
@@ -61,21 +31,23 @@ func main() {
 	c.ForWindow(Criteria{Class: ".*"}, Border(4))
 	c.HideEdgeBorders(Both)
 
-	c.Colors(&ColorConfig{
-		Focused:         ConstantColorClass(Blue),
-		FocusedInactive: ConstantColorClass(Background),
-		Unfocused:       ConstantColorClass(Background),
-		Urgent:          ConstantColorClass(Red),
-		Placeholder:     ConstantColorClass(Green),
-		Background:      Background,
+	theme := NordTheme
+	theme.Font = "pango:DejaVu Sans Mono 11"
+	c.WallpaperTheme("/home/adam/.config/adam/wallpaper", theme)
+	c.MinContrast(4.5)
+	c.RequireContrast(3)
+	c.ThemeFile("~/.config/dunst/dunstrc", func(t Theme) Generator {
+		return &Dunstrc{Theme: t}
+	})
+	c.ThemeFile("~/.config/rofi/theme.rasi", func(t Theme) Generator {
+		return &RofiTheme{Theme: t}
 	})
 
 	c.FocusFollowsMouse(false)
-	c.Font("pango:DejaVu Sans Mono 11")
 
 	c.FloatingModifier("$mod")
 
-	c.BindSym("$mod+Shift+r", c.Recompile("/home/adam/.config/i3/config"))
+	c.BindSym("$mod+Shift+r", c.Recompile(configPath))
 	// c.BindSym("$mod+Shift+r", Exec("make -C ~/.config/i3"), Restart)
 
 	c.BindSym("$mod+Return", Exec(term))
@@ -137,31 +109,6 @@ func main() {
 		bc.Position(Top)
 		bc.StatusCommand("$HOME/go/bin/i3gobar")
 		bc.TrayOutput("primary")
-		bc.Colors(&BarColorConfig{
-			Background: Background,
-			StatusLine: Foreground,
-			Separator:  Foreground,
-			FocusedWorkspace: &BarWorkspaceColor{
-				Border:     Blue,
-				Background: Blue,
-				Text:       Background,
-			},
-			ActiveWorkspace: &BarWorkspaceColor{
-				Border:     Blue,
-				Background: Background,
-				Text:       Foreground,
-			},
-			InactiveWorkspace: &BarWorkspaceColor{
-				Border:     Background,
-				Background: Background,
-				Text:       Foreground,
-			},
-			UrgentWorkspace: &BarWorkspaceColor{
-				Border:     Red,
-				Background: Red,
-				Text:       Background,
-			},
-		})
 	})
 
 	quake(c, "zsh", "$mod+grave", "zsh")
@@ -180,7 +127,13 @@ func main() {
 	c.BindSym("$mod+Shift+p", Exec("maim -s --format=png /dev/stdout | xclip -selection clipboard -t image/png -i"))
 
 	c.BindSym("$mod+u", Exec("cat ~/.config/adam/bookmarks | sort | rofi -dmenu -i -p sites | xargs -r surf"))
-	c.BindSym("$mod+Shift+b", Exec("find ~/Pictures/wallpapers -type f | rofi -dmenu -i -p Wallpaper > ~/.config/adam/wallpaper && feh --bg-fill \"$(cat ~/.config/adam/wallpaper)\""))
+	c.BindSym("$mod+Shift+b", c.ExecFunc(func() error {
+		err := exec.Command("sh", "-c", "find ~/Pictures/wallpapers -type f | rofi -dmenu -i -p Wallpaper > ~/.config/adam/wallpaper && feh --bg-fill \"$(cat ~/.config/adam/wallpaper)\"").Run()
+		if err != nil {
+			return err
+		}
+		return c.ApplyFunc(configPath)
+	}))
 
 	c.BindSym("$mod+Shift+l", Exec("find ~/.screenlayout -type f | rofi -dmenu -i -p Layout | xargs -r sh && i3-msg restart"))
 
@@ -258,7 +211,7 @@ func quake(c *Config, name, keys, command string) {
 }
 
 func execTerm(cmd string) *Command {
-	return Exec(fmt.Sprintf(`%s -e zsh -c "%s"`, term, cmd))
+	return ExecArgs(term, "-e", "zsh", "-c", cmd)
 }
 
 func transpose(slice [][]string) [][]string {
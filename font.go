@@ -1,5 +1,16 @@
 package i3config
 
+import "strings"
+
+type fontLine string
+
+func (f fontLine) Generate() string {
+	return "font " + string(f)
+}
+
 func (c *Config) Font(font string) {
-	c.raw("font " + font)
+	if strings.TrimSpace(font) == "" {
+		c.problem(callerSource(), "empty font")
+	}
+	c.AddLine(fontLine(font))
 }
@@ -9,6 +9,9 @@ type Gaps struct {
 }
 
 func (c *Config) Gaps(gaps Gaps) {
+	if gaps.Inner < 0 || gaps.Outer < 0 {
+		c.problem(callerSource(), "gaps must not be negative, got inner %d and outer %d", gaps.Inner, gaps.Outer)
+	}
 	if gaps.Inner > 0 {
 		c.raw(fmt.Sprintf("gaps inner %d", gaps.Inner))
 	}
@@ -3,13 +3,24 @@ package i3config
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 type Config struct {
 	path   string
-	lines  []Generator
+	lines  []*line
 	chords Chords
 
+	translation *keyTranslation
+	contrast    *contrastLimits
+	output      *outputOptions
+	// problems are found while the config is built, before Validate
+	problems  []*Problem
+	theme     *Theme
+	wallpaper *wallpaperSource
+	files     []*companionFile
+
 	subConfig bool
 	funcs     map[string]func() error
 	binName   string
@@ -19,29 +30,46 @@ type Generator interface {
 	Generate() string
 }
 
+type line struct {
+	generator Generator
+	source    Source
+}
+
 func New(path string) *Config {
 	return &Config{
-		path:      path,
-		lines:     []Generator{},
-		chords:    Chords{},
-		subConfig: false,
-		funcs:     map[string]func() error{},
-		binName:   "config-bin",
+		path:        path,
+		lines:       []*line{},
+		chords:      Chords{},
+		translation: &keyTranslation{},
+		contrast:    &contrastLimits{},
+		output:      &outputOptions{},
+		subConfig:   false,
+		funcs:       map[string]func() error{},
+		binName:     "config-bin",
 	}
 }
 
 func (c *Config) newSubConfig() *Config {
 	sc := New(c.path)
 	sc.subConfig = true
+	sc.translation = c.translation
+	sc.contrast = c.contrast
+	sc.output = c.output
 	return sc
 }
 
+// Variable is set with `set`, or with `set_from_resource` when Resource is
+// set, then Value is the fallback.
 type Variable struct {
-	Name  string
-	Value string
+	Name     string
+	Value    string
+	Resource string
 }
 
 func (v *Variable) Generate() string {
+	if v.Resource != "" {
+		return "set_from_resource " + v.Name + " " + v.Resource + " " + v.Value
+	}
 	return "set " + v.Name + " " + v.Value
 }
 func (c *Config) Set(variable, value string) {
@@ -50,14 +78,78 @@ func (c *Config) Set(variable, value string) {
 		Value: value,
 	})
 }
+
+// substituteVariables replaces variables in str the way i3 does, as text
+// anywhere in the config, mode names included.
+func substituteVariables(str string, vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	// replace $mod_shift before $mod
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		str = strings.ReplaceAll(str, name, vars[name])
+	}
+	return str
+}
+
 func (c *Config) AddLine(g Generator) {
-	c.lines = append(c.lines, g)
+	c.addLine(g, callerSource())
 }
 
+func (c *Config) addLine(g Generator, source Source) {
+	c.lines = append(c.lines, &line{
+		generator: g,
+		source:    source,
+	})
+}
+
+// Build applies chords and returns the generated config, or a
+// ValidationError listing every error in it.
+func (c *Config) Build() (string, error) {
+	c.applyChords()
+	err := c.Validate()
+	if err != nil {
+		return "", err
+	}
+	return c.Generate(), nil
+}
+
+// Generate applies chords and returns the config. The output only depends on
+// the order lines were added in.
 func (c *Config) Generate() string {
+	if !c.subConfig {
+		c.applyChords()
+	}
 	src := ""
-	for _, b := range c.lines {
-		src += b.Generate() + "\n"
+	seen := map[string]bool{}
+	separate := false
+	for _, l := range c.lines {
+		switch l.generator.(type) {
+		case sectionEnd:
+			separate = true
+			continue
+		case *sectionHeader:
+			separate = false
+		}
+		line := l.generator.Generate()
+		if c.output.dedupe && isDuplicate(l, line, seen) {
+			continue
+		}
+		if separate {
+			src += "\n"
+			separate = false
+		}
+		if c.output.annotate {
+			src += c.annotation(l)
+		}
+		src += line + "\n"
 	}
 	return src
 }
@@ -0,0 +1,50 @@
+// Package i3configtest helps testing Go i3 configs by comparing the generated
+// config to a golden file.
+package i3configtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abibby/i3config"
+	"github.com/stretchr/testify/assert"
+)
+
+// update is prefixed so it does not clash with an -update flag of the tests
+// using the package.
+var update = flag.Bool("i3config.update", false, "write the generated configs to their golden files")
+
+// Golden fails the test when the generated config is not the same as the
+// golden file. Run the tests with -i3config.update to write the generated
+// config to the golden file instead.
+func Golden(t testing.TB, c *i3config.Config, file string) {
+	t.Helper()
+	GoldenString(t, c.Generate(), file)
+}
+
+// GoldenString is Golden for any generated file, like the ones added with
+// Config.File.
+func GoldenString(t testing.TB, src, file string) {
+	t.Helper()
+	if *update {
+		err := os.MkdirAll(filepath.Dir(file), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(file, []byte(src), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist, run the tests with -i3config.update to create it", file)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(b), src, "generated config differs from %s, run the tests with -i3config.update to update it", file)
+}
@@ -0,0 +1,65 @@
+package i3configtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/abibby/i3config"
+	"github.com/stretchr/testify/assert"
+)
+
+func config() *i3config.Config {
+	c := i3config.New("config.go")
+	c.Dedupe()
+	c.Set("$mod", "Mod4")
+	c.Section("Apps", func(c *i3config.Config) {
+		c.BindSym("$mod+Return", i3config.Exec("alacritty"))
+		c.BindChord("$mod+o", "f", i3config.Exec("firefox"))
+		c.BindChord("$mod+a", "t", i3config.Exec("thunderbird"))
+		c.BindChord("$mod+e", "c", i3config.Exec("code"))
+	})
+	for _, name := range []string{"zsh", "node"} {
+		c.ForWindow(i3config.Criteria{Instance: "quake_term"}, i3config.FloatingEnabled)
+		c.BindSym("$mod+"+name[:1], i3config.Exec("alacritty --class quake_term -e "+name))
+	}
+	return c
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, config(), "testdata/config.golden")
+}
+
+func TestGoldenDeterministic(t *testing.T) {
+	src := config().Generate()
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, src, config().Generate())
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nested", "config.golden")
+	*update = true
+	defer func() { *update = false }()
+
+	Golden(t, config(), file)
+	*update = false
+	Golden(t, config(), file)
+}
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	r := &recorder{TB: t}
+	GoldenString(r, "bindsym Mod4+q kill\n", "testdata/config.golden")
+	assert.True(t, r.failed)
+}
@@ -3,8 +3,11 @@ package i3config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/abibby/nulls"
 	"github.com/pkg/errors"
@@ -44,7 +47,7 @@ func I3msg(commands ...*Command) error {
 	strCommands := []string{}
 
 	for _, cmd := range commands {
-		strCommands = append(strCommands, cmd.Generate())
+		strCommands = append(strCommands, cmd.String())
 	}
 	err := i3msg(&r, strings.Join(strCommands, "; "))
 	if err != nil {
@@ -97,33 +100,140 @@ func GetOutputs() ([]*I3msgOutput, error) {
 	return o, err
 }
 
+type NodeType string
+
+const (
+	NodeRoot        NodeType = "root"
+	NodeOutput      NodeType = "output"
+	NodeCon         NodeType = "con"
+	NodeFloatingCon NodeType = "floating_con"
+	NodeWorkspace   NodeType = "workspace"
+	NodeDockarea    NodeType = "dockarea"
+)
+
+type NodeLayout string
+
+const (
+	NodeLayoutDefault  NodeLayout = "default"
+	NodeLayoutSplitH   NodeLayout = "splith"
+	NodeLayoutSplitV   NodeLayout = "splitv"
+	NodeLayoutStacked  NodeLayout = "stacked"
+	NodeLayoutTabbed   NodeLayout = "tabbed"
+	NodeLayoutDockarea NodeLayout = "dockarea"
+	NodeLayoutOutput   NodeLayout = "output"
+)
+
+type Orientation string
+
+const (
+	OrientationNone       Orientation = "none"
+	OrientationHorizontal Orientation = "horizontal"
+	OrientationVertical   Orientation = "vertical"
+)
+
+type NodeBorder string
+
+const (
+	NodeBorderNormal NodeBorder = "normal"
+	NodeBorderNone   NodeBorder = "none"
+	NodeBorderPixel  NodeBorder = "pixel"
+)
+
+type ScratchpadState string
+
+const (
+	ScratchpadNone    ScratchpadState = "none"
+	ScratchpadFresh   ScratchpadState = "fresh"
+	ScratchpadChanged ScratchpadState = "changed"
+)
+
+// FloatingState records both whether a container floats and whether that was
+// decided by i3 (auto) or requested by the user.
+type FloatingState string
+
+const (
+	FloatingAutoOff FloatingState = "auto_off"
+	FloatingAutoOn  FloatingState = "auto_on"
+	FloatingUserOff FloatingState = "user_off"
+	FloatingUserOn  FloatingState = "user_on"
+)
+
+func (f FloatingState) Floating() bool {
+	return f == FloatingAutoOn || f == FloatingUserOn
+}
+
+type FullscreenMode int
+
+const (
+	FullscreenNone   FullscreenMode = 0
+	FullscreenOutput FullscreenMode = 1
+	FullscreenGlobal FullscreenMode = 2
+)
+
+type WindowProperties struct {
+	Class        string     `json:"class"`
+	Instance     string     `json:"instance"`
+	Title        string     `json:"title"`
+	WindowRole   string     `json:"window_role"`
+	Machine      string     `json:"machine"`
+	TransientFor *nulls.Int `json:"transient_for"`
+}
+
+// Swallow is one set of criteria a placeholder container uses to capture a
+// new window. The string fields are regular expressions.
+type Swallow struct {
+	Class       string     `json:"class,omitempty"`
+	Instance    string     `json:"instance,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	WindowRole  string     `json:"window_role,omitempty"`
+	Machine     string     `json:"machine,omitempty"`
+	ID          *nulls.Int `json:"id,omitempty"`
+	Dock        *nulls.Int `json:"dock,omitempty"`
+	InsertWhere *nulls.Int `json:"insert_where,omitempty"`
+}
+
+type NodeGaps struct {
+	Inner  int `json:"inner"`
+	Outer  int `json:"outer"`
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+	Left   int `json:"left"`
+}
+
 type I3msgNode struct {
-	ID                 int64         `json:"id"`
-	Type               string        `json:"type"`
-	Orientation        string        `json:"orientation"`
-	ScratchpadState    string        `json:"scratchpad_state"`
-	Percent            float64       `json:"percent"`
-	Urgent             bool          `json:"urgent"`
-	Focused            bool          `json:"focused"`
-	Layout             string        `json:"layout"`
-	WorkspaceLayout    string        `json:"workspace_layout"`
-	LastSplitLayout    string        `json:"last_split_layout"`
-	Border             string        `json:"border"`
-	CurrentBorderWidth int           `json:"current_border_width"`
-	Rect               Rect          `json:"rect"`
-	DecoRect           Rect          `json:"deco_rect"`
-	WindowRect         Rect          `json:"window_rect"`
-	Geometry           Rect          `json:"geometry"`
-	Name               string        `json:"name"`
-	Window             *nulls.Int    `json:"window"`
-	WindowType         *nulls.String `json:"window_type"`
-	Nodes              []*I3msgNode  `json:"nodes"`
-	FloatingNodes      []*I3msgNode  `json:"floating_nodes"`
-	Focus              []int64       `json:"focus"`
-	FullscreenMode     int           `json:"fullscreen_mode"`
-	Sticky             bool          `json:"sticky"`
-	Floating           string        `json:"floating"`
-	Swallows           []interface{} `json:"swallows"`
+	ID                 int64             `json:"id"`
+	Type               NodeType          `json:"type"`
+	Orientation        Orientation       `json:"orientation"`
+	ScratchpadState    ScratchpadState   `json:"scratchpad_state"`
+	Percent            float64           `json:"percent"`
+	Urgent             bool              `json:"urgent"`
+	Marks              []string          `json:"marks"`
+	Focused            bool              `json:"focused"`
+	Output             string            `json:"output"`
+	Layout             NodeLayout        `json:"layout"`
+	WorkspaceLayout    NodeLayout        `json:"workspace_layout"`
+	LastSplitLayout    NodeLayout        `json:"last_split_layout"`
+	Border             NodeBorder        `json:"border"`
+	CurrentBorderWidth int               `json:"current_border_width"`
+	Rect               Rect              `json:"rect"`
+	DecoRect           Rect              `json:"deco_rect"`
+	WindowRect         Rect              `json:"window_rect"`
+	Geometry           Rect              `json:"geometry"`
+	Name               string            `json:"name"`
+	Num                *nulls.Int        `json:"num"`
+	Gaps               *NodeGaps         `json:"gaps"`
+	WindowIconPadding  int               `json:"window_icon_padding"`
+	Window             *nulls.Int        `json:"window"`
+	WindowType         WindowType        `json:"window_type"`
+	WindowProperties   *WindowProperties `json:"window_properties"`
+	Nodes              []*I3msgNode      `json:"nodes"`
+	FloatingNodes      []*I3msgNode      `json:"floating_nodes"`
+	Focus              []int64           `json:"focus"`
+	FullscreenMode     FullscreenMode    `json:"fullscreen_mode"`
+	Sticky             bool              `json:"sticky"`
+	Floating           FloatingState     `json:"floating"`
+	Swallows           []*Swallow        `json:"swallows"`
 }
 
 func GetTree() (*I3msgNode, error) {
@@ -143,3 +253,132 @@ func (n *I3msgNode) Walk(cb func(n *I3msgNode) bool) {
 		subNode.Walk(cb)
 	}
 }
+
+type I3msgModeEvent struct {
+	Change      string `json:"change"`
+	PangoMarkup bool   `json:"pango_markup"`
+}
+
+type I3msgBindingEvent struct {
+	Change  string        `json:"change"`
+	Binding *I3msgBinding `json:"binding"`
+}
+
+// I3msgBinding is the binding that ran. Symbol is nil for bindcode bindings
+// and EventStateMask lists the modifiers the binding was declared with.
+type I3msgBinding struct {
+	Command        string   `json:"command"`
+	EventStateMask []string `json:"event_state_mask"`
+	InputCode      int      `json:"input_code"`
+	Symbol         *string  `json:"symbol"`
+	InputType      string   `json:"input_type"`
+}
+
+// I3msgEvent is one event from a subscription, only the field of the event's
+// type is set.
+type I3msgEvent struct {
+	Mode    *I3msgModeEvent
+	Binding *I3msgBindingEvent
+}
+
+func (e *I3msgEvent) UnmarshalJSON(b []byte) error {
+	probe := struct {
+		PangoMarkup *bool         `json:"pango_markup"`
+		Binding     *I3msgBinding `json:"binding"`
+	}{}
+	err := json.Unmarshal(b, &probe)
+	if err != nil {
+		return err
+	}
+	if probe.Binding != nil {
+		e.Binding = &I3msgBindingEvent{}
+		return json.Unmarshal(b, e.Binding)
+	}
+	if probe.PangoMarkup != nil {
+		e.Mode = &I3msgModeEvent{}
+		return json.Unmarshal(b, e.Mode)
+	}
+	return nil
+}
+
+// Subscribe calls cb for every event of the given types, like "mode" or
+// "binding", until the subscription ends, which happens when i3 restarts or
+// exits.
+func Subscribe(events []string, cb func(e *I3msgEvent) error) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("i3-msg", "-t", "subscribe", "-m", string(b))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	d := json.NewDecoder(stdout)
+	result := &CommandResult{}
+	err = d.Decode(result)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
+	}
+	if !result.Success {
+		return fmt.Errorf("failed to subscribe to %s events", strings.Join(events, ", "))
+	}
+	for {
+		e := &I3msgEvent{}
+		err = d.Decode(e)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to parse")
+		}
+		err = cb(e)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// subscribeForever keeps subscribing across i3 restarts and gives up once i3
+// has been gone for a while. Errors from cb are logged.
+func subscribeForever(events []string, cb func(e *I3msgEvent) error) error {
+	failures := 0
+	for failures < 10 {
+		subscribed := false
+		err := Subscribe(events, func(e *I3msgEvent) error {
+			subscribed = true
+			err := cb(e)
+			if err != nil {
+				log.Print(err)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Print(err)
+		}
+		if subscribed {
+			failures = 0
+		} else {
+			failures++
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("lost connection to i3")
+}
+
+// SubscribeModes calls cb every time the binding mode changes until the
+// subscription ends.
+func SubscribeModes(cb func(e *I3msgModeEvent) error) error {
+	return Subscribe([]string{"mode"}, func(e *I3msgEvent) error {
+		if e.Mode == nil {
+			return nil
+		}
+		return cb(e.Mode)
+	})
+}
@@ -0,0 +1,100 @@
+package i3config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTree(t *testing.T) *I3msgNode {
+	t.Helper()
+	b, err := os.ReadFile("testdata/get_tree.json")
+	require.NoError(t, err)
+
+	tree := &I3msgNode{}
+	require.NoError(t, json.Unmarshal(b, tree))
+	return tree
+}
+
+func findNode(tree *I3msgNode, match func(n *I3msgNode) bool) *I3msgNode {
+	var found *I3msgNode
+	tree.Walk(func(n *I3msgNode) bool {
+		if found == nil && match(n) {
+			found = n
+		}
+		return found != nil
+	})
+	return found
+}
+
+func TestI3msgNodeUnmarshal(t *testing.T) {
+	tree := loadTree(t)
+
+	assert.Equal(t, NodeRoot, tree.Type)
+	assert.Equal(t, "", tree.Output)
+	assert.Len(t, tree.Nodes, 2)
+
+	t.Run("workspace", func(t *testing.T) {
+		ws := findNode(tree, func(n *I3msgNode) bool {
+			return n.Type == NodeWorkspace && n.Name == "1"
+		})
+		require.NotNil(t, ws)
+		assert.Equal(t, 1, ws.Num.Value())
+		assert.Equal(t, "eDP-1", ws.Output)
+		assert.Equal(t, FullscreenOutput, ws.FullscreenMode)
+		require.NotNil(t, ws.Gaps)
+		assert.Equal(t, 10, ws.Gaps.Inner)
+		assert.Len(t, ws.FloatingNodes, 1)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		n := findNode(tree, func(n *I3msgNode) bool { return n.Focused })
+		require.NotNil(t, n)
+		assert.Equal(t, []string{"term", "_hidden"}, n.Marks)
+		assert.Equal(t, Normal, n.WindowType)
+		assert.Equal(t, NodeBorderPixel, n.Border)
+		assert.Equal(t, OrientationNone, n.Orientation)
+		assert.Equal(t, FloatingAutoOff, n.Floating)
+		assert.False(t, n.Floating.Floating())
+		require.NotNil(t, n.WindowProperties)
+		assert.Equal(t, "Alacritty", n.WindowProperties.Class)
+		assert.Nil(t, n.WindowProperties.TransientFor)
+	})
+
+	t.Run("dialog", func(t *testing.T) {
+		n := findNode(tree, func(n *I3msgNode) bool { return n.WindowType == Dialog })
+		require.NotNil(t, n)
+		assert.Equal(t, FloatingAutoOn, n.Floating)
+		assert.True(t, n.Floating.Floating())
+		assert.True(t, n.Sticky)
+		assert.Equal(t, "GtkFileChooserDialog", n.WindowProperties.WindowRole)
+		assert.Equal(t, 67108869, n.WindowProperties.TransientFor.Value())
+	})
+
+	t.Run("scratchpad", func(t *testing.T) {
+		n := findNode(tree, func(n *I3msgNode) bool { return n.Type == NodeFloatingCon && n.ScratchpadState == ScratchpadChanged })
+		require.NotNil(t, n)
+		assert.Equal(t, FloatingUserOn, n.Floating)
+		assert.Equal(t, "notes", n.Nodes[0].Marks[0])
+	})
+
+	t.Run("swallows", func(t *testing.T) {
+		placeholder := findNode(tree, func(n *I3msgNode) bool {
+			return len(n.Swallows) > 0 && n.Type == NodeCon
+		})
+		require.NotNil(t, placeholder)
+		assert.Equal(t, "^Firefox$", placeholder.Swallows[0].Class)
+		assert.Equal(t, "^Navigator$", placeholder.Swallows[0].Instance)
+		assert.Nil(t, placeholder.Swallows[0].Dock)
+
+		dock := findNode(tree, func(n *I3msgNode) bool { return n.Type == NodeDockarea })
+		require.NotNil(t, dock)
+		assert.Equal(t, NodeLayoutDockarea, dock.Layout)
+		require.Len(t, dock.Swallows, 1)
+		assert.Equal(t, 2, dock.Swallows[0].Dock.Value())
+		assert.Equal(t, Dock, dock.Nodes[0].WindowType)
+	})
+}
@@ -0,0 +1,74 @@
+// colorgen builds the color name table from the X11 rgb.txt database.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var colorRegExp = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+(\d+)\s+(.+?)\s*$`)
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("usage: colorgen <output> <rgb.txt>")
+	}
+
+	f, err := os.Open(os.Args[2])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	colors := map[string]string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		m := colorRegExp.FindStringSubmatch(s.Text())
+		if m == nil || strings.HasPrefix(s.Text(), "!") {
+			continue
+		}
+		// "ghost white" and "GhostWhite" are both listed
+		name := strings.ToLower(strings.ReplaceAll(m[4], " ", ""))
+		hex := "#"
+		for _, c := range m[1:4] {
+			v, err := strconv.ParseUint(c, 10, 8)
+			if err != nil {
+				log.Fatal(err)
+			}
+			hex += fmt.Sprintf("%02x", v)
+		}
+		colors[name] = hex
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	b.WriteString("// Code generated by internal/colorgen; DO NOT EDIT.\n\npackage i3config\n\n")
+	b.WriteString("var namedColors = map[string]Color{\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "%q: %q,\n", name, colors[name])
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile(os.Args[1], src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+// keysymgen builds the keysym name table from the X11 keysym headers.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var defineRegExp = regexp.MustCompile(`^#define\s+(XF86)?XK_(\w+)\s+(0x[0-9a-fA-F]+|_EVDEVK\((0x[0-9a-fA-F]+)\))`)
+
+func main() {
+	if len(os.Args) < 3 {
+		log.Fatal("usage: keysymgen <output> <header>...")
+	}
+
+	keysyms := map[string]uint64{}
+	for _, header := range os.Args[2:] {
+		f, err := os.Open(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			m := defineRegExp.FindStringSubmatch(s.Text())
+			if m == nil {
+				continue
+			}
+			name := m[1] + m[2]
+			var value uint64
+			if m[4] != "" {
+				value, err = strconv.ParseUint(m[4], 0, 32)
+				value += 0x10081000
+			} else {
+				value, err = strconv.ParseUint(m[3], 0, 32)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			keysyms[name] = value
+		}
+		f.Close()
+	}
+
+	names := make([]string, 0, len(keysyms))
+	for name := range keysyms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	b.WriteString("// Code generated by internal/keysymgen; DO NOT EDIT.\n\npackage i3config\n\n")
+	b.WriteString("var keysyms = map[string]uint32{\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "%q: 0x%x,\n", name, keysyms[name])
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile(os.Args[1], src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,230 @@
+package i3config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keymap is the keycode to keysym mapping of an XKB keymap, as printed by
+// `xkbcomp $DISPLAY -`. It is used to write keysym bindings as the keycodes
+// that produce them on one layout, so a binding stays on the same physical
+// key whatever layout is active.
+type Keymap struct {
+	Name string
+	// Group is the XKB group (layout) that keysyms are looked up in,
+	// starting at 1.
+	Group int
+
+	keycodes map[string]int
+	symbols  map[string][][]string
+}
+
+func LoadKeymap(file string) (*Keymap, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeymap(string(b))
+}
+
+var (
+	keycodeRegExp  = regexp.MustCompile(`<([^>]+)>\s*=\s*(\d+)\s*;`)
+	aliasRegExp    = regexp.MustCompile(`alias\s+<([^>]+)>\s*=\s*<([^>]+)>\s*;`)
+	keyRegExp      = regexp.MustCompile(`key\s+<([^>]+)>\s*\{`)
+	groupRegExp    = regexp.MustCompile(`(\w+)\[\s*[Gg]roup(\d+)\s*\]\s*=\s*\[([^\]]*)\]`)
+	bareListRegExp = regexp.MustCompile(`\[([^\]]*)\]`)
+)
+
+func ParseKeymap(src string) (*Keymap, error) {
+	km := &Keymap{
+		Group:    1,
+		keycodes: map[string]int{},
+		symbols:  map[string][][]string{},
+	}
+
+	_, keycodes, err := xkbSection(src, "xkb_keycodes")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range keycodeRegExp.FindAllStringSubmatch(keycodes, -1) {
+		code, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, err
+		}
+		km.keycodes[m[1]] = code
+	}
+	for _, m := range aliasRegExp.FindAllStringSubmatch(keycodes, -1) {
+		if code, ok := km.keycodes[m[2]]; ok {
+			km.keycodes[m[1]] = code
+		}
+	}
+
+	name, symbols, err := xkbSection(src, "xkb_symbols")
+	if err != nil {
+		return nil, err
+	}
+	km.Name = name
+	for _, loc := range keyRegExp.FindAllStringSubmatchIndex(symbols, -1) {
+		key := symbols[loc[2]:loc[3]]
+		end := matchingBrace(symbols, loc[1]-1)
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated key <%s>", key)
+		}
+		km.symbols[key] = parseKeySymbols(symbols[loc[1]:end])
+	}
+	return km, nil
+}
+
+// xkbSection returns the name and body of a section like
+// `xkb_symbols "pc+us" { ... };`.
+func xkbSection(src, section string) (string, string, error) {
+	loc := regexp.MustCompile(section + `\s*("[^"]*")?\s*\{`).FindStringSubmatchIndex(src)
+	if loc == nil {
+		return "", "", fmt.Errorf("keymap has no %s section", section)
+	}
+	name := ""
+	if loc[2] != -1 {
+		name = strings.Trim(src[loc[2]:loc[3]], `"`)
+	}
+	end := matchingBrace(src, loc[1]-1)
+	if end == -1 {
+		return "", "", fmt.Errorf("unterminated %s section", section)
+	}
+	return name, src[loc[1]:end], nil
+}
+
+func matchingBrace(src string, open int) int {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseKeySymbols reads the keysyms of each group from the body of a key
+// block. Groups are either named, symbols[Group2]= [ ... ], or listed in
+// order, { [ a, A ], [ b, B ] }.
+func parseKeySymbols(body string) [][]string {
+	groups := [][]string{}
+	set := func(group int, levels []string) {
+		for len(groups) < group {
+			groups = append(groups, nil)
+		}
+		groups[group-1] = levels
+	}
+
+	named := false
+	for _, m := range groupRegExp.FindAllStringSubmatch(body, -1) {
+		if m[1] != "symbols" {
+			continue
+		}
+		group, _ := strconv.Atoi(m[2])
+		set(group, splitKeysyms(m[3]))
+		named = true
+	}
+	if named {
+		return groups
+	}
+
+	body = groupRegExp.ReplaceAllString(body, "")
+	for i, m := range bareListRegExp.FindAllStringSubmatch(body, -1) {
+		set(i+1, splitKeysyms(m[1]))
+	}
+	return groups
+}
+
+func splitKeysyms(list string) []string {
+	levels := []string{}
+	for _, sym := range strings.Split(list, ",") {
+		levels = append(levels, strings.TrimSpace(sym))
+	}
+	return levels
+}
+
+// Lookup finds the keycode that produces keysym in the keymap's group and
+// the shift level it is on, 0 for unshifted. Unshifted keys are preferred,
+// then the lowest keycode.
+func (k *Keymap) Lookup(keysym string) (int, int, bool) {
+	type match struct{ code, level int }
+	matches := []match{}
+	for key, groups := range k.symbols {
+		code, ok := k.keycodes[key]
+		if !ok || len(groups) < k.Group {
+			continue
+		}
+		for level, sym := range groups[k.Group-1] {
+			if sym == keysym {
+				matches = append(matches, match{code, level})
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return 0, 0, false
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].level != matches[j].level {
+			return matches[i].level < matches[j].level
+		}
+		return matches[i].code < matches[j].code
+	})
+	return matches[0].code, matches[0].level, true
+}
+
+// Translate rewrites the key of a combo as a keycode, adding Shift when the
+// keysym is on the shifted level.
+func (k *Keymap) Translate(combo KeyCombo) (KeyCombo, error) {
+	if combo.IsButton() {
+		return combo, fmt.Errorf("%s is a mouse button", combo)
+	}
+	if isVariable(combo.Key) {
+		return combo, fmt.Errorf("can not translate variable %s in %s", combo.Key, combo)
+	}
+	code, level, ok := k.Lookup(canonicalKeysym(combo.Key))
+	if !ok {
+		return combo, fmt.Errorf("keysym %s is not on keymap %s", combo.Key, k.Name)
+	}
+	if level > 1 {
+		return combo, fmt.Errorf("keysym %s is on level %d of keymap %s", combo.Key, level+1, k.Name)
+	}
+
+	translated := KeyCombo{
+		Modifiers: append([]Modifier{}, combo.Modifiers...),
+		Key:       fmt.Sprint(code),
+	}
+	if level == 1 && !translated.HasModifier(ModShift) {
+		translated.Modifiers = append(translated.Modifiers, ModShift)
+	}
+	translated.sort()
+	return translated, nil
+}
+
+// keyTranslation is shared by a config and all of its sub configs so that
+// the keymap can be set after bindings are declared.
+type keyTranslation struct {
+	keymap *Keymap
+	all    bool
+}
+
+// Keymap sets the layout used to turn keysym bindings into keycodes.
+func (c *Config) Keymap(km *Keymap) {
+	c.translation.keymap = km
+}
+
+// ToCode writes every keysym binding, in every mode, as a keycode binding
+// using the keymap set with Keymap.
+func (c *Config) ToCode() {
+	c.translation.all = true
+}
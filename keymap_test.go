@@ -0,0 +1,148 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadKeymap(t *testing.T, name string) *Keymap {
+	t.Helper()
+	km, err := LoadKeymap("testdata/keymaps/" + name + ".xkb")
+	require.NoError(t, err)
+	return km
+}
+
+func TestKeymapLookup(t *testing.T) {
+	testCases := []struct {
+		keymap string
+		keysym string
+		code   int
+		level  int
+	}{
+		{"us", "q", 24, 0},
+		{"us", "Q", 24, 1},
+		{"us", "s", 39, 0},
+		{"us", "exclam", 10, 1},
+		{"us", "Escape", 9, 0},
+		{"us", "backslash", 51, 0},
+		{"dvorak", "apostrophe", 24, 0},
+		{"dvorak", "s", 47, 0},
+		{"dvorak", "o", 39, 0},
+		{"colemak", "r", 39, 0},
+		{"colemak", "BackSpace", 22, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.keymap+" "+tc.keysym, func(t *testing.T) {
+			code, level, ok := loadKeymap(t, tc.keymap).Lookup(tc.keysym)
+			assert.True(t, ok)
+			assert.Equal(t, tc.code, code)
+			assert.Equal(t, tc.level, level)
+		})
+	}
+}
+
+func TestKeymapTranslate(t *testing.T) {
+	testCases := []struct {
+		keymap string
+		keys   string
+		code   string
+		err    string
+	}{
+		{"us", "$mod+q", "$mod+24", ""},
+		{"dvorak", "$mod+apostrophe", "$mod+24", ""},
+		{"colemak", "Mod4+f", "Mod4+26", ""},
+		{"us", "Mod4+exclam", "Shift+Mod4+10", ""},
+		{"us", "Mod4+Shift+exclam", "Shift+Mod4+10", ""},
+		{"us", "Mod4+return", "Mod4+36", ""},
+		{"us", "button1", "", "button1 is a mouse button"},
+		{"us", "$mod+$key", "", "can not translate variable $key in $mod+$key"},
+		{"us", "XF86AudioPlay", "", "keysym XF86AudioPlay is not on keymap pc+us+inet(evdev)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.keymap+" "+tc.keys, func(t *testing.T) {
+			code, err := loadKeymap(t, tc.keymap).Translate(parseKeyCombo(tc.keys))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.code, code.String())
+		})
+	}
+}
+
+func TestParseKeymapErrors(t *testing.T) {
+	_, err := ParseKeymap(`xkb_keymap { xkb_symbols "pc+us" { }; };`)
+	assert.EqualError(t, err, "keymap has no xkb_keycodes section")
+
+	_, err = ParseKeymap(`xkb_keycodes "evdev" { <AE01> = 10;`)
+	assert.EqualError(t, err, "unterminated xkb_keycodes section")
+}
+
+func TestBindToCode(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.Keymap(loadKeymap(t, "dvorak"))
+	c.BindSym("$mod+apostrophe", Kill).ToCode()
+	c.BindSym("$mod+s", Kill)
+	c.BindSym("$mod+r", Mode("resize"))
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("h", ResizeShrink(Width, 10)).ToCode()
+		c.BindSym("Escape", Mode("default"))
+	})
+
+	assert.Equal(t, `set $mod Mod4
+bindcode $mod+24 kill
+bindsym $mod+s kill
+bindsym $mod+r mode "resize"
+mode "resize" {
+    bindcode 44 resize shrink width 10 px or 10 ppt
+    bindsym Escape mode "default"
+    `+`
+}
+`, c.Generate())
+	assert.Empty(t, c.Problems())
+}
+
+func TestConfigToCode(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindSym("$mod+s", Kill)
+	c.BindSym("button3", FloatingEnabled)
+	c.BindChord("$mod+r", "o", Reload)
+	c.ToCode()
+	c.Keymap(loadKeymap(t, "colemak"))
+	c.applyChords()
+
+	assert.Equal(t, `set $mod Mod4
+bindcode $mod+40 kill
+bindsym button3 floating enabled
+bindcode $mod+39 mode "Chord: $mod+r"
+mode "Chord: $mod+r" {
+    bindcode 47 mode "default"; reload
+    bindcode 9 mode "default"
+    `+`
+}
+`, c.Generate())
+}
+
+func TestToCodeLint(t *testing.T) {
+	c := New("config.go")
+	c.BindSym("Mod4+q", Kill).ToCode()
+	assert.Empty(t, c.Problems())
+	assert.Equal(t, "bindsym --to-code Mod4+q kill\n", c.Generate())
+
+	c.Keymap(loadKeymap(t, "us"))
+	assert.Empty(t, c.Problems())
+
+	c.BindSym("Mod4+XF86AudioPlay", Kill).ToCode()
+	c.BindSym("button3", Kill).ToCode()
+	problems := c.Problems()
+	require.Len(t, problems, 2)
+	assert.True(t, problems[0].Warning)
+	assert.Equal(t, "keysym XF86AudioPlay is not on keymap pc+us+inet(evdev), it is written with --to-code", problems[0].Message)
+	assert.Equal(t, "button3 is a mouse button and has no keycode", problems[1].Message)
+	assert.Equal(t, "bindcode Mod4+24 kill\nbindsym --to-code Mod4+XF86AudioPlay kill\nbindsym button3 kill\n", c.Generate())
+}
@@ -0,0 +1,287 @@
+package i3config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:generate go run ./internal/keysymgen keysyms.go /usr/include/X11/keysymdef.h /usr/include/X11/XF86keysym.h
+
+type Modifier string
+
+const (
+	ModShift      Modifier = "Shift"
+	ModLock       Modifier = "Lock"
+	ModControl    Modifier = "Control"
+	Mod1          Modifier = "Mod1"
+	Mod2          Modifier = "Mod2"
+	Mod3          Modifier = "Mod3"
+	Mod4          Modifier = "Mod4"
+	Mod5          Modifier = "Mod5"
+	ModGroup1     Modifier = "Group1"
+	ModGroup2     Modifier = "Group2"
+	ModGroup3     Modifier = "Group3"
+	ModGroup4     Modifier = "Group4"
+	ModModeSwitch Modifier = "Mode_switch"
+)
+
+var modifiers = []Modifier{
+	ModShift,
+	ModLock,
+	ModControl,
+	Mod1,
+	Mod2,
+	Mod3,
+	Mod4,
+	Mod5,
+	ModGroup1,
+	ModGroup2,
+	ModGroup3,
+	ModGroup4,
+	ModModeSwitch,
+}
+
+var modifierAliases = map[string]Modifier{
+	"ctrl": ModControl,
+}
+
+func lookupModifier(name string) (Modifier, bool) {
+	if mod, ok := modifierAliases[strings.ToLower(name)]; ok {
+		return mod, true
+	}
+	for _, mod := range modifiers {
+		if strings.EqualFold(string(mod), name) {
+			return mod, true
+		}
+	}
+	return "", false
+}
+
+func modifierIndex(mod Modifier) int {
+	for i, m := range modifiers {
+		if m == mod {
+			return i
+		}
+	}
+	return len(modifiers)
+}
+
+// KeyCombo is a key combination like $mod+Shift+Left. Modifiers and the key
+// may be $variables set with Config.Set until the combo is resolved.
+type KeyCombo struct {
+	Modifiers []Modifier
+	Key       string
+}
+
+func isVariable(str string) bool {
+	return strings.HasPrefix(str, "$")
+}
+
+// ParseKeyCombo parses a combination of modifiers and a key separated by +.
+// Modifier names are matched case insensitively and ctrl is accepted for
+// Control.
+func ParseKeyCombo(keys string) (KeyCombo, error) {
+	k := parseKeyCombo(keys)
+	if k.Key == "" {
+		return k, fmt.Errorf("missing key in %q", keys)
+	}
+	for _, mod := range k.Modifiers {
+		if mod == "" {
+			return k, fmt.Errorf("empty modifier in %q", keys)
+		}
+	}
+	return k, nil
+}
+
+func parseKeyCombo(keys string) KeyCombo {
+	parts := strings.Split(keys, "+")
+	// a trailing + is the plus key
+	if len(parts) > 1 && parts[len(parts)-1] == "" && parts[len(parts)-2] == "" {
+		parts = append(parts[:len(parts)-2], "plus")
+	}
+	k := KeyCombo{
+		Modifiers: []Modifier{},
+		Key:       parts[len(parts)-1],
+	}
+	for _, part := range parts[:len(parts)-1] {
+		if mod, ok := lookupModifier(part); ok {
+			k.Modifiers = append(k.Modifiers, mod)
+		} else {
+			k.Modifiers = append(k.Modifiers, Modifier(part))
+		}
+	}
+	k.sort()
+	return k
+}
+
+// sort puts variables first, in the order they were written, followed by
+// the modifiers in X's order.
+func (k KeyCombo) sort() {
+	sort.SliceStable(k.Modifiers, func(i, j int) bool {
+		a, b := k.Modifiers[i], k.Modifiers[j]
+		if isVariable(string(a)) || isVariable(string(b)) {
+			return isVariable(string(a)) && !isVariable(string(b))
+		}
+		return modifierIndex(a) < modifierIndex(b)
+	})
+}
+
+func (k KeyCombo) String() string {
+	parts := make([]string, 0, len(k.Modifiers)+1)
+	for _, mod := range k.Modifiers {
+		parts = append(parts, string(mod))
+	}
+	return strings.Join(append(parts, k.Key), "+")
+}
+
+func (k KeyCombo) HasModifier(mod Modifier) bool {
+	for _, m := range k.Modifiers {
+		if m == mod {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve substitutes variables with their values. A variable may stand for
+// several modifiers, like `set $hyper Mod4+Shift`.
+func (k KeyCombo) Resolve(vars map[string]string) (KeyCombo, error) {
+	resolved := KeyCombo{Modifiers: []Modifier{}}
+	add := func(mod Modifier) {
+		if !resolved.HasModifier(mod) {
+			resolved.Modifiers = append(resolved.Modifiers, mod)
+		}
+	}
+	for _, mod := range k.Modifiers {
+		if !isVariable(string(mod)) {
+			add(mod)
+			continue
+		}
+		value, ok := vars[string(mod)]
+		if !ok {
+			return k, fmt.Errorf("unknown variable %s in %s", mod, k)
+		}
+		for _, part := range strings.Split(value, "+") {
+			m, ok := lookupModifier(part)
+			if !ok {
+				return k, fmt.Errorf("%s=%s is not a modifier in %s", mod, value, k)
+			}
+			add(m)
+		}
+	}
+
+	resolved.Key = k.Key
+	if isVariable(k.Key) {
+		value, ok := vars[k.Key]
+		if !ok {
+			return k, fmt.Errorf("unknown variable %s in %s", k.Key, k)
+		}
+		inner := parseKeyCombo(value)
+		for _, mod := range inner.Modifiers {
+			add(mod)
+		}
+		resolved.Key = inner.Key
+	}
+	resolved.sort()
+	return resolved, nil
+}
+
+var buttonRegExp = regexp.MustCompile(`^button[1-9]$`)
+
+// Validate checks that every modifier is known and that the key is a keysym
+// name or mouse button. Variables must be resolved first.
+func (k KeyCombo) Validate() error {
+	err := k.validateModifiers()
+	if err != nil {
+		return err
+	}
+	return k.checkKeysym()
+}
+
+func (k KeyCombo) validateModifiers() error {
+	for _, mod := range k.Modifiers {
+		if isVariable(string(mod)) {
+			return fmt.Errorf("unresolved variable %s in %s", mod, k)
+		}
+		if _, ok := lookupModifier(string(mod)); !ok {
+			return fmt.Errorf("unknown modifier %s in %s", mod, k)
+		}
+	}
+	return nil
+}
+
+// checkKeysym reports a key that is neither a keysym nor a mouse button. A
+// key that is a variable is checked once it is resolved.
+func (k KeyCombo) checkKeysym() error {
+	if isVariable(k.Key) || k.IsButton() {
+		return nil
+	}
+	if _, ok := keysyms[canonicalKeysym(k.Key)]; !ok {
+		return fmt.Errorf("unknown keysym %s in %s", k.Key, k)
+	}
+	return nil
+}
+
+// ValidateCode checks a combo used with bindcode, where the key is an X
+// keycode.
+func (k KeyCombo) ValidateCode() error {
+	for _, mod := range k.Modifiers {
+		if _, ok := lookupModifier(string(mod)); !ok {
+			return fmt.Errorf("unknown modifier %s in %s", mod, k)
+		}
+	}
+	code, err := strconv.Atoi(k.Key)
+	if err != nil || code < 8 || code > 255 {
+		return fmt.Errorf("invalid keycode %s in %s", k.Key, k)
+	}
+	return nil
+}
+
+func (k KeyCombo) IsButton() bool {
+	return buttonRegExp.MatchString(k.Key)
+}
+
+// Normalize returns a resolved combo in the form used to compare bindings.
+// Keysym names are matched case insensitively and an upper case letter
+// becomes Shift and the lower case letter.
+func (k KeyCombo) Normalize() KeyCombo {
+	n := KeyCombo{
+		Modifiers: append([]Modifier{}, k.Modifiers...),
+		Key:       k.Key,
+	}
+	if runes := []rune(n.Key); len(runes) == 1 && unicode.IsUpper(runes[0]) {
+		n.Key = strings.ToLower(n.Key)
+		if !n.HasModifier(ModShift) {
+			n.Modifiers = append(n.Modifiers, ModShift)
+		}
+	} else {
+		n.Key = canonicalKeysym(n.Key)
+	}
+	n.sort()
+	return n
+}
+
+var foldedKeysyms = func() map[string][]string {
+	folded := map[string][]string{}
+	for sym := range keysyms {
+		lower := strings.ToLower(sym)
+		folded[lower] = append(folded[lower], sym)
+	}
+	return folded
+}()
+
+// canonicalKeysym returns the keysym spelled like name, ignoring case when
+// that is unambiguous.
+func canonicalKeysym(name string) string {
+	if _, ok := keysyms[name]; ok {
+		return name
+	}
+	if syms := foldedKeysyms[strings.ToLower(name)]; len(syms) == 1 {
+		return syms[0]
+	}
+	return name
+}
@@ -0,0 +1,80 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCombo(t *testing.T) {
+	vars := map[string]string{"$mod": "Mod4", "$hyper": "Mod4+Shift", "$left": "h"}
+	testCases := []struct {
+		keys       string
+		canonical  string
+		normalized string
+	}{
+		{"$mod+Shift+Left", "$mod+Shift+Left", "Shift+Mod4+Left"},
+		{"shift+$mod+left", "$mod+Shift+left", "Shift+Mod4+Left"},
+		{"$mod+A", "$mod+A", "Shift+Mod4+a"},
+		{"Mod1+ctrl+x", "Control+Mod1+x", "Control+Mod1+x"},
+		{"$hyper+$left", "$hyper+$left", "Shift+Mod4+h"},
+		{"Mod4++", "Mod4+plus", "Mod4+plus"},
+		{"escape", "escape", "Escape"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.keys, func(t *testing.T) {
+			k, err := ParseKeyCombo(tc.keys)
+			require.NoError(t, err)
+			assert.Equal(t, tc.canonical, k.String())
+
+			resolved, err := k.Resolve(vars)
+			require.NoError(t, err)
+			assert.NoError(t, resolved.Validate())
+			assert.Equal(t, tc.normalized, resolved.Normalize().String())
+		})
+	}
+}
+
+func TestKeyComboErrors(t *testing.T) {
+	_, err := ParseKeyCombo("Mod4+")
+	assert.Error(t, err)
+
+	_, err = parseKeyCombo("$nope+a").Resolve(map[string]string{})
+	assert.EqualError(t, err, "unknown variable $nope in $nope+a")
+
+	assert.EqualError(t, parseKeyCombo("Mod4+Retrun").Validate(), "unknown keysym Retrun in Mod4+Retrun")
+	assert.EqualError(t, parseKeyCombo("Hyper+a").Validate(), "unknown modifier Hyper in Hyper+a")
+	assert.NoError(t, parseKeyCombo("Group2+XF86AudioPlay").Validate())
+	assert.NoError(t, parseKeyCombo("Mod4+button3").Validate())
+}
+
+func TestUnknownKeysymReported(t *testing.T) {
+	c := New("config.go")
+	c.Set("$key", "Retrun")
+	c.BindSym("Mod4+Retrun", Kill)
+	bindLine := currentLine() - 1
+	c.BindChord("Mod4+o", "Retrun", Kill)
+	chordLine := currentLine() - 1
+	c.BindSym("Mod4+$key", Kill)
+	varLine := currentLine() - 1
+	c.BindSym("Mod4+Left", FocusLeft).Alias("Mod4+Lfet")
+	aliasLine := currentLine() - 1
+
+	// keys are reported when the binding is added, keys with a variable
+	// once it is resolved
+	require.Len(t, c.problems, 3)
+	// applying the chords does not report them again
+	c.Generate()
+	require.Len(t, c.Problems(), 4)
+	messages := map[int]string{}
+	for _, p := range c.Problems() {
+		messages[p.Source.Line] = p.Message
+	}
+	assert.Equal(t, map[int]string{
+		bindLine:  "unknown keysym Retrun in Mod4+Retrun",
+		chordLine: "unknown keysym Retrun in Retrun",
+		varLine:   "unknown keysym Retrun in Mod4+Retrun",
+		aliasLine: "unknown keysym Lfet in Mod4+Lfet",
+	}, messages)
+}
@@ -0,0 +1,201 @@
+package i3config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// LayoutNode is a container as understood by i3's append_layout command.
+// Containers that hold a window are written as placeholders that swallow the
+// next window matching their criteria.
+type LayoutNode struct {
+	Type               NodeType      `json:"type"`
+	Name               string        `json:"name,omitempty"`
+	Layout             NodeLayout    `json:"layout,omitempty"`
+	Orientation        Orientation   `json:"orientation,omitempty"`
+	Percent            float64       `json:"percent,omitempty"`
+	Border             NodeBorder    `json:"border,omitempty"`
+	CurrentBorderWidth int           `json:"current_border_width,omitempty"`
+	Floating           FloatingState `json:"floating,omitempty"`
+	Geometry           *Rect         `json:"geometry,omitempty"`
+	Marks              []string      `json:"marks,omitempty"`
+	Nodes              []*LayoutNode `json:"nodes,omitempty"`
+	Swallows           []*Swallow    `json:"swallows,omitempty"`
+}
+
+// SwallowOptions picks which window properties are used to build the swallow
+// criteria of a placeholder.
+type SwallowOptions struct {
+	Class      bool
+	Instance   bool
+	Title      bool
+	WindowRole bool
+	Machine    bool
+}
+
+var DefaultSwallowOptions = SwallowOptions{
+	Class:    true,
+	Instance: true,
+}
+
+// LayoutNodes converts the contents of a workspace into the containers that
+// append_layout expects. Floating containers are included after the tiling
+// ones.
+func (n *I3msgNode) LayoutNodes(opts SwallowOptions) []*LayoutNode {
+	nodes := []*LayoutNode{}
+	for _, child := range n.Nodes {
+		nodes = append(nodes, child.layoutNode(opts))
+	}
+	for _, child := range n.FloatingNodes {
+		nodes = append(nodes, child.layoutNode(opts))
+	}
+	return nodes
+}
+
+func (n *I3msgNode) layoutNode(opts SwallowOptions) *LayoutNode {
+	l := &LayoutNode{
+		Type:               n.Type,
+		Layout:             n.Layout,
+		Orientation:        n.Orientation,
+		Percent:            n.Percent,
+		Border:             n.Border,
+		CurrentBorderWidth: n.CurrentBorderWidth,
+		Floating:           n.Floating,
+		Marks:              n.Marks,
+		Nodes:              n.LayoutNodes(opts),
+	}
+	if n.Type == NodeFloatingCon {
+		rect := n.Rect
+		l.Geometry = &rect
+	}
+	if n.WindowProperties != nil {
+		l.Name = n.Name
+		l.Swallows = []*Swallow{swallowFor(n.WindowProperties, opts)}
+		l.Nodes = nil
+		if n.Geometry.Width > 0 && n.Geometry.Height > 0 {
+			rect := n.Geometry
+			l.Geometry = &rect
+		}
+	}
+	if len(l.Nodes) == 0 {
+		l.Nodes = nil
+	}
+	if len(l.Marks) == 0 {
+		l.Marks = nil
+	}
+	return l
+}
+
+func swallowFor(p *WindowProperties, opts SwallowOptions) *Swallow {
+	s := &Swallow{}
+	if opts.Class && p.Class != "" {
+		s.Class = exactRegexp(p.Class)
+	}
+	if opts.Instance && p.Instance != "" {
+		s.Instance = exactRegexp(p.Instance)
+	}
+	if opts.Title && p.Title != "" {
+		s.Title = exactRegexp(p.Title)
+	}
+	if opts.WindowRole && p.WindowRole != "" {
+		s.WindowRole = exactRegexp(p.WindowRole)
+	}
+	if opts.Machine && p.Machine != "" {
+		s.Machine = exactRegexp(p.Machine)
+	}
+	return s
+}
+
+func exactRegexp(str string) string {
+	return "^" + regexp.QuoteMeta(str) + "$"
+}
+
+// WriteLayout writes nodes in the format produced by i3-save-tree, one top
+// level container after another.
+func WriteLayout(w io.Writer, nodes []*LayoutNode) error {
+	for _, n := range nodes {
+		b, err := json.MarshalIndent(n, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadLayout parses a layout file containing any number of top level
+// containers.
+func ReadLayout(r io.Reader) ([]*LayoutNode, error) {
+	nodes := []*LayoutNode{}
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		n := &LayoutNode{}
+		err := dec.Decode(n)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse layout")
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func (n *I3msgNode) FindWorkspace(name string) *I3msgNode {
+	var ws *I3msgNode
+	n.Walk(func(n *I3msgNode) bool {
+		if n.Type == NodeWorkspace && n.Name == name {
+			ws = n
+		}
+		return ws != nil || n.Type == NodeWorkspace
+	})
+	return ws
+}
+
+// SaveLayout captures the live layout of a workspace and writes it to file.
+func SaveLayout(workspace, file string, opts SwallowOptions) error {
+	tree, err := GetTree()
+	if err != nil {
+		return err
+	}
+	ws := tree.FindWorkspace(workspace)
+	if ws == nil {
+		return fmt.Errorf("no workspace named %s", workspace)
+	}
+
+	buf := &bytes.Buffer{}
+	err = WriteLayout(buf, ws.LayoutNodes(opts))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+func AppendLayout(file string) *Command {
+	return newCommand("append_layout", quoted(file))
+}
+
+// RestoreLayout loads a saved layout into workspace on startup and then runs
+// programs, which are expected to fill the layout's placeholders.
+func (c *Config) RestoreLayout(workspace, file string, programs ...*Command) {
+	c.OnStartup(c.ExecFunc(func() error {
+		err := I3msg(Workspace(workspace), AppendLayout(file))
+		if err != nil {
+			return err
+		}
+		for _, program := range programs {
+			err = I3msg(program)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}).NoStartupID())
+}
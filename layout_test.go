@@ -0,0 +1,43 @@
+package i3config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutNodes(t *testing.T) {
+	ws := loadTree(t).FindWorkspace("1")
+	require.NotNil(t, ws)
+
+	nodes := ws.LayoutNodes(DefaultSwallowOptions)
+	require.Len(t, nodes, 3)
+
+	term := nodes[0]
+	assert.Equal(t, []*Swallow{{Class: "^Alacritty$", Instance: "^Alacritty$"}}, term.Swallows)
+	assert.Equal(t, []string{"term", "_hidden"}, term.Marks)
+	assert.Nil(t, term.Nodes)
+
+	tabs := nodes[1]
+	assert.Equal(t, NodeLayoutTabbed, tabs.Layout)
+	assert.Nil(t, tabs.Swallows)
+	require.Len(t, tabs.Nodes, 2)
+	assert.Equal(t, "^Mailspring$", tabs.Nodes[0].Swallows[0].Class)
+
+	floating := nodes[2]
+	assert.Equal(t, NodeFloatingCon, floating.Type)
+	assert.Equal(t, &Rect{X: 760, Y: 440, Width: 400, Height: 200}, floating.Geometry)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteLayout(buf, nodes))
+	read, err := ReadLayout(buf)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, read)
+}
+
+func TestSwallowForEscapesRegexp(t *testing.T) {
+	s := swallowFor(&WindowProperties{Title: "a.b (1)"}, SwallowOptions{Title: true})
+	assert.Equal(t, `^a\.b \(1\)$`, s.Title)
+}
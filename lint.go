@@ -0,0 +1,123 @@
+package i3config
+
+import "fmt"
+
+// variables returns the values set with Set.
+func (c *Config) variables() map[string]string {
+	vars := map[string]string{}
+	for _, l := range c.lines {
+		if v, ok := l.generator.(*Variable); ok {
+			vars[v.Name] = v.Value
+		}
+	}
+	return vars
+}
+
+type declaredBinding struct {
+	keys   KeyCombo
+	source Source
+}
+
+// bindingKey resolves and normalizes keys, reporting keys that i3 would not
+// accept. It returns "" when the keys can not be compared.
+func bindingKey(v *validator, bindType string, keys KeyCombo, source Source) string {
+	resolved, err := keys.Resolve(v.vars)
+	if err != nil {
+		v.report(source, err.Error())
+		return ""
+	}
+	switch {
+	case bindType == "bindcode":
+		err = resolved.ValidateCode()
+	case isVariable(keys.Key):
+		err = resolved.Validate()
+	default:
+		// unknown keysyms are reported when the binding is added
+		err = resolved.validateModifiers()
+	}
+	if err != nil {
+		v.report(source, err.Error())
+		return ""
+	}
+	return resolved.Normalize().String()
+}
+
+func (c *Config) lintBindings(v *validator, mode string) {
+	bound := map[string]*declaredBinding{}
+	declare := func(bindType string, keys KeyCombo, release bool, source Source) {
+		normalized := bindingKey(v, bindType, keys, source)
+		if normalized == "" {
+			return
+		}
+		id := fmt.Sprintf("%s %t %s", bindType, release, normalized)
+		if first, ok := bound[id]; ok {
+			v.report(source, fmt.Sprintf("%s in mode %q is already bound at %s", keys, mode, first.source))
+			return
+		}
+		bound[id] = &declaredBinding{keys: keys, source: source}
+
+		other := fmt.Sprintf("%s %t %s", bindType, !release, normalized)
+		if first, ok := bound[other]; ok {
+			v.warn(source, fmt.Sprintf("%s in mode %q overlaps the binding at %s, one is --release", keys, mode, first.source))
+		}
+	}
+
+	for _, l := range c.lines {
+		switch g := l.generator.(type) {
+		case *Bind:
+			lintBindFlags(v, g, l.source)
+			for _, keys := range g.combos() {
+				declare(g.bindType, keys, g.release, l.source)
+			}
+		case *ModeType:
+			g.config.lintBindings(v, g.name)
+		}
+	}
+
+	for _, key1 := range c.chords.keys() {
+		chords := c.chords[key1]
+		normalized := bindingKey(v, "bindsym", parseKeyCombo(key1), chords[0].source)
+		if first, ok := bound["bindsym false "+normalized]; ok && normalized != "" {
+			v.report(chords[0].source, fmt.Sprintf("chord %s in mode %q shadows the binding at %s", key1, mode, first.source))
+		}
+
+		seen := map[string]Source{}
+		for _, bc := range chords {
+			normalized := bindingKey(v, "bindsym", bc.keys, bc.source)
+			if normalized == "" {
+				continue
+			}
+			if first, ok := seen[normalized]; ok {
+				v.report(bc.source, fmt.Sprintf("chord %s %s is already bound at %s", key1, bc.keys, first))
+				continue
+			}
+			seen[normalized] = bc.source
+		}
+	}
+}
+
+func lintBindFlags(v *validator, b *Bind, source Source) {
+	if (b.border || b.wholeWindow || b.excludeTitlebar) && !b.keys.IsButton() {
+		v.warn(source, fmt.Sprintf("%s is not a mouse button, --border, --whole-window and --exclude-titlebar have no effect", b.keys))
+	}
+	if b.toCode && b.bindType != "bindsym" {
+		v.warn(source, fmt.Sprintf("%s is already a keycode binding", b.keys))
+		return
+	}
+	if b.toCode && b.keys.IsButton() {
+		v.warn(source, fmt.Sprintf("%s is a mouse button and has no keycode", b.keys))
+		return
+	}
+	if b.translation == nil || b.translation.keymap == nil {
+		// i3 translates them with --to-code
+		return
+	}
+	for _, keys := range b.combos() {
+		if !b.wantsCode(keys) {
+			continue
+		}
+		if _, err := b.keycode(keys); err != nil {
+			v.warn(source, err.Error()+", it is written with --to-code")
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintBindings(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindSym("$mod+Shift+a", Kill)
+	firstLine := currentLine() - 1
+	c.BindSym("$mod+h", FocusLeft).Alias("Mod4+A")
+	aliasLine := currentLine() - 1
+	c.BindSym("$mod+h", FocusRight).Release()
+	releaseLine := currentLine() - 1
+	c.BindSym("$mod+b", Kill)
+	bLine := currentLine() - 1
+	c.BindChord("$mod+b", "x", Kill)
+	chordLine := currentLine() - 1
+	c.BindSym("$mod+r", Mode("resize"))
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+		c.BindSym("escape", Mode("default"))
+	})
+	modeLine := currentLine() - 2
+
+	problems := c.Problems()
+	messages := map[int]string{}
+	for _, p := range problems {
+		messages[p.Source.Line] = p.String()
+	}
+	file := problems[0].Source.File
+
+	assert.Len(t, problems, 4)
+	assert.Contains(t, messages[aliasLine], "Mod4+A in mode \"default\" is already bound at "+Source{File: file, Line: firstLine}.String())
+	assert.Contains(t, messages[releaseLine], "warning: $mod+h in mode \"default\" overlaps")
+	assert.Contains(t, messages[modeLine], "escape in mode \"resize\" is already bound at ")
+	assert.Contains(t, messages[chordLine], "chord $mod+b in mode \"default\" shadows the binding at "+Source{File: file, Line: bLine}.String())
+}
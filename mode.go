@@ -6,14 +6,18 @@ type ModeType struct {
 }
 
 func (c *Config) Mode(name string, mode func(c *Config)) {
+	c.AddLine(c.newMode(name, mode))
+}
+
+func (c *Config) newMode(name string, mode func(c *Config)) *ModeType {
 	subConfig := c.newSubConfig()
 	mode(subConfig)
-	c.AddLine(&ModeType{
+	return &ModeType{
 		name:   name,
 		config: subConfig,
-	})
+	}
 }
 
 func (m ModeType) Generate() string {
-	return "mode " + escapeString(m.name) + " {\n" + indent(m.config.Generate()) + "\n}"
+	return "mode " + quoteConfig(m.name) + " {\n" + indent(m.config.Generate()) + "\n}"
 }
@@ -0,0 +1,148 @@
+package i3config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModeGraph is the set of binding modes and the bindings that switch between
+// them. Mode names have their variables substituted, as i3 does.
+type ModeGraph struct {
+	Modes []string
+	Edges []*ModeEdge
+
+	sources map[string]Source
+}
+
+// ModeEdge is a binding in mode From that switches to mode To.
+type ModeEdge struct {
+	From   string
+	To     string
+	Keys   KeyCombo
+	Source Source
+}
+
+// ModeGraph builds the graph of every mode declared with Mode or BindChord
+// and every binding that runs a mode command.
+func (c *Config) ModeGraph() *ModeGraph {
+	g := &ModeGraph{
+		Modes:   []string{"default"},
+		Edges:   []*ModeEdge{},
+		sources: map[string]Source{},
+	}
+	c.modeGraph(g, c.variables(), "default")
+	return g
+}
+
+func (g *ModeGraph) addMode(name string, source Source) {
+	if _, ok := g.sources[name]; ok || name == "default" {
+		return
+	}
+	g.Modes = append(g.Modes, name)
+	g.sources[name] = source
+}
+
+func (c *Config) modeGraph(g *ModeGraph, vars map[string]string, mode string) {
+	addEdges := func(from string, keys KeyCombo, commands []*Command, source Source) {
+		if resolved, err := keys.Resolve(vars); err == nil {
+			keys = resolved
+		}
+		for _, cmd := range commands {
+			name, ok := cmd.ModeName()
+			if !ok {
+				continue
+			}
+			g.Edges = append(g.Edges, &ModeEdge{
+				From:   from,
+				To:     substituteVariables(name, vars),
+				Keys:   keys,
+				Source: source,
+			})
+		}
+	}
+
+	for _, l := range c.lines {
+		switch gen := l.generator.(type) {
+		case *Bind:
+			for _, keys := range gen.combos() {
+				addEdges(mode, keys, gen.commands, l.source)
+			}
+		case *ModeType:
+			name := substituteVariables(gen.name, vars)
+			g.addMode(name, l.source)
+			gen.config.modeGraph(g, vars, name)
+		}
+	}
+
+	// chords become a mode per leading key when the config is generated
+	for _, key1 := range c.chords.keys() {
+		chords := c.chords[key1]
+		name := substituteVariables("Chord: "+key1, vars)
+		g.addMode(name, chords[0].source)
+		addEdges(mode, parseKeyCombo(key1), []*Command{Mode(name)}, chords[0].source)
+		for _, bc := range chords {
+			addEdges(name, bc.keys, append([]*Command{Mode("default")}, bc.commands...), bc.source)
+		}
+		addEdges(name, KeyCombo{Modifiers: []Modifier{}, Key: "Escape"}, []*Command{Mode("default")}, chords[0].source)
+	}
+}
+
+// reachable returns the modes that can be reached from start, following
+// edges forwards or, when reverse is set, backwards.
+func (g *ModeGraph) reachable(start string, reverse bool) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		mode := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Edges {
+			from, to := e.From, e.To
+			if reverse {
+				from, to = to, from
+			}
+			if from == mode && !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return seen
+}
+
+func (g *ModeGraph) lint(v *validator) {
+	for _, e := range g.Edges {
+		if _, ok := g.sources[e.To]; !ok && e.To != "default" {
+			v.report(e.Source, fmt.Sprintf("%s in mode %q switches to mode %q which does not exist", e.Keys, e.From, e.To))
+		}
+	}
+
+	reachable := g.reachable("default", false)
+	exits := g.reachable("default", true)
+	for _, mode := range g.Modes[1:] {
+		if !reachable[mode] {
+			// a mode that is only entered with i3-msg can not trap a binding
+			v.warn(g.sources[mode], fmt.Sprintf("mode %q can not be reached from the default mode", mode))
+			continue
+		}
+		if !exits[mode] {
+			v.report(g.sources[mode], fmt.Sprintf("mode %q has no way back to the default mode", mode))
+		}
+	}
+}
+
+func dotString(str string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+}
+
+// DOT returns the graph in Graphviz's dot language, with the keys of each
+// binding as the edge labels.
+func (g *ModeGraph) DOT() string {
+	lines := []string{"digraph modes {"}
+	for _, mode := range g.Modes {
+		lines = append(lines, "    "+dotString(mode)+";")
+	}
+	for _, e := range g.Edges {
+		lines = append(lines, fmt.Sprintf("    %s -> %s [label=%s];", dotString(e.From), dotString(e.To), dotString(e.Keys.String())))
+	}
+	return strings.Join(append(lines, "}"), "\n") + "\n"
+}
@@ -0,0 +1,68 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeGraphDOT(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.Set("$resize", "resize")
+	c.BindSym("$mod+r", Mode("$resize"))
+	c.BindChord("$mod+o", "f", Kill)
+	c.Mode("$resize", func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+		c.BindSym("q", Mode(`say "hi"`))
+	})
+	c.Mode(`say "hi"`, func(c *Config) {
+		c.BindSym("Return", Mode("default"))
+	})
+
+	assert.Equal(t, `digraph modes {
+    "default";
+    "resize";
+    "say \"hi\"";
+    "Chord: Mod4+o";
+    "default" -> "resize" [label="Mod4+r"];
+    "resize" -> "default" [label="Escape"];
+    "resize" -> "say \"hi\"" [label="q"];
+    "say \"hi\"" -> "default" [label="Return"];
+    "default" -> "Chord: Mod4+o" [label="Mod4+o"];
+    "Chord: Mod4+o" -> "default" [label="f"];
+    "Chord: Mod4+o" -> "default" [label="Escape"];
+}
+`, c.ModeGraph().DOT())
+	assert.Empty(t, c.Problems())
+}
+
+func TestModeGraphLint(t *testing.T) {
+	c := New("config.go")
+	c.BindSym("Mod4+r", Mode("resize"))
+	c.BindSym("Mod4+g", Mode("gaps"))
+	gapsLine := currentLine() - 1
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("h", ResizeShrink(Width, 10))
+	})
+	resizeLine := currentLine() - 3
+	c.Mode("unused", func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+	})
+	unusedLine := currentLine() - 3
+	c.Mode("external", func(c *Config) {
+		c.BindSym("h", ResizeShrink(Width, 10))
+	})
+	externalLine := currentLine() - 3
+
+	messages := map[int][]string{}
+	for _, p := range c.Problems() {
+		messages[p.Source.Line] = append(messages[p.Source.Line], p.Message)
+	}
+	assert.Equal(t, map[int][]string{
+		gapsLine:     {`Mod4+g in mode "default" switches to mode "gaps" which does not exist`},
+		resizeLine:   {`mode "resize" has no way back to the default mode`},
+		unusedLine:   {`mode "unused" can not be reached from the default mode`},
+		externalLine: {`mode "external" can not be reached from the default mode`},
+	}, messages)
+}
@@ -0,0 +1,249 @@
+package i3config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Palette is a terminal color scheme: the 16 ANSI colors plus the special
+// colors. It can be read from Xresources, base16 and pywal files.
+type Palette struct {
+	Name       string
+	Background Color
+	Foreground Color
+	Cursor     Color
+	Colors     [16]Color
+}
+
+// fill defaults the special colors to the ANSI ones and checks that every
+// color is set.
+func (p *Palette) fill() error {
+	if p.Background == "" {
+		p.Background = p.Colors[0]
+	}
+	if p.Foreground == "" {
+		p.Foreground = p.Colors[7]
+	}
+	if p.Cursor == "" {
+		p.Cursor = p.Foreground
+	}
+	for i, c := range p.Colors {
+		if c == "" {
+			return fmt.Errorf("palette %s is missing color%d", p.Name, i)
+		}
+	}
+	return nil
+}
+
+// Theme uses the background and foreground of the palette with blue as the
+// accent, red for urgent windows and bright black for inactive ones.
+func (p *Palette) Theme() Theme {
+	return Theme{
+		Name:       p.Name,
+		Background: p.Background,
+		Foreground: p.Foreground,
+		Accent:     p.Colors[4],
+		Urgent:     p.Colors[1],
+		Inactive:   p.Colors[8],
+	}
+}
+
+// set sets the color called name, other names are ignored.
+func (p *Palette) set(name, value string) error {
+	var target *Color
+	switch name {
+	case "background":
+		target = &p.Background
+	case "foreground":
+		target = &p.Foreground
+	case "cursor", "cursorColor":
+		target = &p.Cursor
+	default:
+		m := ansiColorRegExp.FindStringSubmatch(name)
+		if m == nil {
+			return nil
+		}
+		index, _ := strconv.Atoi(m[1])
+		target = &p.Colors[index]
+	}
+	c, err := ParseColor(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	*target = c
+	return nil
+}
+
+var (
+	ansiColorRegExp = regexp.MustCompile(`^color([0-9]|1[0-5])$`)
+	xresourceRegExp = regexp.MustCompile(`^([\w.*?-]*[.*])?([\w-]+)\s*:\s*(.*?)\s*$`)
+	xdefineRegExp   = regexp.MustCompile(`^#\s*define\s+(\w+)\s+(.*?)\s*$`)
+)
+
+func LoadXresources(file string) (*Palette, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseXresources(string(b))
+}
+
+// ParseXresources reads the colors of an Xresources file like
+// `*.color4: #81a1c1`. Resources for a single program, like URxvt.color4,
+// are read too and #define macros are expanded.
+func ParseXresources(src string) (*Palette, error) {
+	p := &Palette{Name: "xresources"}
+	defines := map[string]string{}
+	s := bufio.NewScanner(strings.NewReader(src))
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if m := xdefineRegExp.FindStringSubmatch(text); m != nil {
+			defines[m[1]] = m[2]
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		m := xresourceRegExp.FindStringSubmatch(text)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: invalid resource %q", line, text)
+		}
+		value := m[3]
+		if v, ok := defines[value]; ok {
+			value = v
+		}
+		err := p.set(m[2], value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	err := s.Err()
+	if err != nil {
+		return nil, err
+	}
+	err = p.fill()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func LoadBase16(file string) (*Palette, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBase16(string(b))
+}
+
+// base16ANSI maps the ANSI colors to base16 slots the way base16-shell does.
+var base16ANSI = [16]string{
+	"base00", "base08", "base0B", "base0A", "base0D", "base0E", "base0C", "base05",
+	"base03", "base08", "base0B", "base0A", "base0D", "base0E", "base0C", "base07",
+}
+
+// ParseBase16 reads a base16 scheme, either the classic format with the
+// colors at the top level or the tinted-theming format with a palette key.
+func ParseBase16(src string) (*Palette, error) {
+	scheme := struct {
+		Scheme  string            `yaml:"scheme"`
+		Name    string            `yaml:"name"`
+		Palette map[string]string `yaml:"palette"`
+	}{}
+	err := yaml.Unmarshal([]byte(src), &scheme)
+	if err != nil {
+		return nil, err
+	}
+	colors := scheme.Palette
+	if colors == nil {
+		colors = map[string]string{}
+		err = yaml.Unmarshal([]byte(src), &colors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Palette{Name: scheme.Scheme}
+	if p.Name == "" {
+		p.Name = scheme.Name
+	}
+	for i, slot := range base16ANSI {
+		value, ok := colors[slot]
+		if !ok {
+			return nil, fmt.Errorf("base16 scheme %s is missing %s", p.Name, slot)
+		}
+		c, err := ParseColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", slot, err)
+		}
+		p.Colors[i] = c
+	}
+	p.Background = p.Colors[0]
+	p.Foreground = p.Colors[7]
+	err = p.fill()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func LoadPywal(file string) (*Palette, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePywal(b)
+}
+
+// ParsePywal reads the colors.json that pywal writes to ~/.cache/wal.
+func ParsePywal(src []byte) (*Palette, error) {
+	wal := struct {
+		Wallpaper string            `json:"wallpaper"`
+		Special   map[string]string `json:"special"`
+		Colors    map[string]string `json:"colors"`
+	}{}
+	err := json.Unmarshal(src, &wal)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Palette{Name: "pywal"}
+	for name, value := range wal.Special {
+		err = p.set(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	for name, value := range wal.Colors {
+		err = p.set(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	err = p.fill()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetFromResource sets variable from an X resource when i3 loads the config,
+// falling back to fallback when the resource is not set. It lets the config
+// follow a palette loaded with `xrdb` without being generated again.
+func (c *Config) SetFromResource(variable, resource, fallback string) {
+	c.AddLine(&Variable{
+		Name:     variable,
+		Value:    fallback,
+		Resource: resource,
+	})
+}
@@ -0,0 +1,164 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPalette(t *testing.T) {
+	testCases := []struct {
+		name        string
+		load        func(string) (*Palette, error)
+		file        string
+		paletteName string
+		background  Color
+		foreground  Color
+		cursor      Color
+		color4      Color
+		color15     Color
+	}{
+		{
+			name:        "xresources",
+			load:        LoadXresources,
+			file:        "testdata/palettes/nord.Xresources",
+			paletteName: "xresources",
+			background:  "#2e3440",
+			foreground:  "#d8dee9",
+			cursor:      "#d8dee9",
+			color4:      "#81a1c1",
+			color15:     "#eceff4",
+		},
+		{
+			name:        "base16",
+			load:        LoadBase16,
+			file:        "testdata/palettes/nord.yaml",
+			paletteName: "Nord",
+			background:  "#2e3440",
+			foreground:  "#e5e9f0",
+			cursor:      "#e5e9f0",
+			color4:      "#81a1c1",
+			color15:     "#8fbcbb",
+		},
+		{
+			name:        "pywal",
+			load:        LoadPywal,
+			file:        "testdata/palettes/colors.json",
+			paletteName: "pywal",
+			background:  "#1d1f21",
+			foreground:  "#c5c8c6",
+			cursor:      "#c5c8c6",
+			color4:      "#81a2be",
+			color15:     "#ffffff",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := tc.load(tc.file)
+			require.NoError(t, err)
+			assert.Equal(t, tc.paletteName, p.Name)
+			assert.Equal(t, tc.background, p.Background)
+			assert.Equal(t, tc.foreground, p.Foreground)
+			assert.Equal(t, tc.cursor, p.Cursor)
+			assert.Equal(t, tc.color4, p.Colors[4])
+			assert.Equal(t, tc.color15, p.Colors[15])
+		})
+	}
+}
+
+func TestParsePaletteErrors(t *testing.T) {
+	errOf := func(_ *Palette, err error) error {
+		return err
+	}
+	testCases := []struct {
+		name string
+		err  error
+		msg  string
+	}{
+		{name: "missing color", err: errOf(ParseXresources("*.color0: #000000\n")), msg: "palette xresources is missing color1"},
+		{name: "invalid color", err: errOf(ParseXresources("*.color0: nope\n")), msg: `line 1: invalid color "nope"`},
+		{name: "invalid line", err: errOf(ParseXresources("color0 #000000\n")), msg: `line 1: invalid resource "color0 #000000"`},
+		{name: "missing slot", err: errOf(ParseBase16("scheme: Empty\nbase00: \"000000\"\n")), msg: "base16 scheme Empty is missing base08"},
+		{name: "invalid json", err: errOf(ParsePywal([]byte("{"))), msg: "unexpected end of JSON input"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.err, tc.msg)
+		})
+	}
+}
+
+func TestParseBase16Palette(t *testing.T) {
+	p, err := ParseBase16(`system: "base16"
+name: "Nord"
+palette:
+  base00: "#2e3440"
+  base01: "#3b4252"
+  base02: "#434c5e"
+  base03: "#4c566a"
+  base04: "#d8dee9"
+  base05: "#e5e9f0"
+  base06: "#eceff4"
+  base07: "#8fbcbb"
+  base08: "#bf616a"
+  base09: "#d08770"
+  base0A: "#ebcb8b"
+  base0B: "#a3be8c"
+  base0C: "#88c0d0"
+  base0D: "#81a1c1"
+  base0E: "#b48ead"
+  base0F: "#5e81ac"
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "Nord", p.Name)
+	assert.Equal(t, Color("#bf616a"), p.Colors[1])
+}
+
+func TestPaletteTheme(t *testing.T) {
+	p, err := LoadXresources("testdata/palettes/nord.Xresources")
+	require.NoError(t, err)
+	assert.Equal(t, Theme{
+		Name:       "xresources",
+		Background: "#2e3440",
+		Foreground: "#d8dee9",
+		Accent:     "#81a1c1",
+		Urgent:     "#bf616a",
+		Inactive:   "#4c566a",
+	}, p.Theme())
+}
+
+func TestSetFromResource(t *testing.T) {
+	c := New("config.go")
+	c.SetFromResource("$bg", "i3wm.background", "#2e3440")
+	c.Colors(&ColorConfig{
+		Focused:         ConstantColorClass("$bg"),
+		FocusedInactive: ConstantColorClass("$bg"),
+		Unfocused:       ConstantColorClass("$bg"),
+		Urgent:          ConstantColorClass("$bg"),
+		Placeholder:     ConstantColorClass("$bg"),
+		Background:      "$bg",
+	})
+
+	assert.NoError(t, c.Validate())
+	assert.Contains(t, c.Generate(), "set_from_resource $bg i3wm.background #2e3440\n")
+}
+
+func TestUndefinedColorVariable(t *testing.T) {
+	c := New("config.go")
+	c.Set("$bg", "#2e3440")
+	line := currentLine() + 1
+	c.Colors(&ColorConfig{
+		Focused:         ConstantColorClass("$bg"),
+		FocusedInactive: ConstantColorClass("$bg"),
+		Unfocused:       ConstantColorClass("$bg"),
+		Urgent:          ConstantColorClass("$bg"),
+		Placeholder:     ConstantColorClass("$bg"),
+		Background:      "$background",
+	})
+
+	problems := c.Problems()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "undefined variable $background for client.background", problems[0].Message)
+	assert.Equal(t, line, problems[0].Source.Line)
+}
@@ -0,0 +1,96 @@
+package i3config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// i3 reads strings at two levels. The config parser reads directives like
+// `mode "name" {` or `for_window [class="x"] ...` and only unescapes \". The
+// command parser reads commands, either from IPC or from the rest of a
+// bindsym/exec/for_window line, and unescapes both \" and \\ inside double
+// quoted strings. A command written in the config file goes through both.
+//
+// Neither level can hold a newline when read from a config file, and a config
+// level string cannot end with a backslash.
+
+// quoteCommand quotes str as a single argument for i3's command parser.
+func quoteCommand(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	str = strings.ReplaceAll(str, `"`, `\"`)
+	return `"` + str + `"`
+}
+
+// parseCommandString reads one argument the way i3's command parser does. A
+// quoted argument ends at the first unescaped quote, an unquoted one at a ;
+// or , or when asWord is set at whitespace or ]. It returns the value and the
+// unparsed remainder.
+func parseCommandString(src string, asWord bool) (string, string, error) {
+	if strings.HasPrefix(src, `"`) {
+		for i := 1; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				if i+1 < len(src) {
+					i++
+				}
+			case '"':
+				return unescapeCommand(src[1:i]), src[i+1:], nil
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string %s", src)
+	}
+
+	end := strings.IndexAny(src, ";,\r\n")
+	if asWord {
+		end = strings.IndexAny(src, " \t];,\r\n")
+	}
+	if end == -1 {
+		end = len(src)
+	}
+	return unescapeCommand(src[:end]), src[end:], nil
+}
+
+func unescapeCommand(str string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) && (str[i+1] == '"' || str[i+1] == '\\') {
+			i++
+		}
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// quoteConfig quotes str as a single value for i3's config parser, as used by
+// mode names and for_window criteria.
+func quoteConfig(str string) string {
+	return `"` + strings.ReplaceAll(str, `"`, `\"`) + `"`
+}
+
+// parseConfigString reads one quoted or unquoted word the way i3's config
+// parser does and returns the value and the unparsed remainder.
+func parseConfigString(src string) (string, string, error) {
+	if strings.HasPrefix(src, `"`) {
+		for i := 1; i < len(src); i++ {
+			if src[i] == '"' && src[i-1] != '\\' {
+				return unescapeConfig(src[1:i]), src[i+1:], nil
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string %s", src)
+	}
+	end := strings.IndexAny(src, " \t];,\r\n")
+	if end == -1 {
+		end = len(src)
+	}
+	return unescapeConfig(src[:end]), src[end:], nil
+}
+
+// configLine escapes a command so that it reads back unchanged when it is the
+// unquoted rest of a config line, as in `bindsym $mod+a <command>`.
+func configLine(command string) string {
+	return strings.ReplaceAll(command, `\"`, `\\"`)
+}
+
+func unescapeConfig(str string) string {
+	return strings.ReplaceAll(str, `\"`, `"`)
+}
@@ -0,0 +1,100 @@
+package i3config
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// configSafe drops the strings i3 cannot read back from a config file.
+func configSafe(str string) bool {
+	return !strings.ContainsAny(str, "\r\n\x00")
+}
+
+func TestQuoteCommandRoundTrip(t *testing.T) {
+	err := quick.Check(func(str string) bool {
+		value, rest, err := parseCommandString(quoteCommand(str)+"; next", false)
+		return err == nil && value == str && rest == "; next"
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestQuoteConfigRoundTrip(t *testing.T) {
+	err := quick.Check(func(str string) bool {
+		if !configSafe(str) || strings.HasSuffix(str, `\`) {
+			return true
+		}
+		value, rest, err := parseConfigString(quoteConfig(str) + " {")
+		return err == nil && value == str && rest == " {"
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestConfigLineRoundTrip(t *testing.T) {
+	err := quick.Check(func(str string) bool {
+		if !configSafe(str) {
+			return true
+		}
+		line := unescapeConfig(configLine("exec " + quoteCommand(str)))
+		value, rest, err := parseCommandString(strings.TrimPrefix(line, "exec "), false)
+		return err == nil && value == str && rest == ""
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestQuoteSpecialCases(t *testing.T) {
+	testCases := []string{``, `"`, `\`, `\"`, `\\"`, `"\`, `a"b\c`, `a;b,c]`}
+	for _, str := range testCases {
+		t.Run(str, func(t *testing.T) {
+			value, _, err := parseCommandString(quoteCommand(str), false)
+			assert.NoError(t, err)
+			assert.Equal(t, str, value)
+
+			line := unescapeConfig(configLine(quoteCommand(str)))
+			value, _, err = parseCommandString(line, false)
+			assert.NoError(t, err)
+			assert.Equal(t, str, value)
+
+			if !strings.HasSuffix(str, `\`) {
+				value, _, err = parseConfigString(quoteConfig(str))
+				assert.NoError(t, err)
+				assert.Equal(t, str, value)
+			}
+		})
+	}
+}
+
+func TestParseUnquoted(t *testing.T) {
+	value, rest, err := parseCommandString(`foo\"bar, baz`, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `foo"bar`, value)
+	assert.Equal(t, ", baz", rest)
+
+	value, rest, err = parseConfigString(`Alacritty]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alacritty", value)
+	assert.Equal(t, "]", rest)
+}
+
+func TestGeneratorsQuoting(t *testing.T) {
+	c := New("config.go")
+	c.ForWindow(Criteria{Class: `^foo\.bar$`}, FloatingEnabled)
+	c.Mode(`say "hi"`, func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+	})
+	c.BindSym("a", MoveContainer(`"1"`), Mode(`say "hi"`))
+	c.WorkspaceOutput(`"1"`, "DP-0")
+
+	assert.Equal(t, `for_window [class="^foo\.bar$"] floating enabled
+mode "say \"hi\"" {
+    bindsym Escape mode "default"
+    `+`
+}
+bindsym a move container to workspace "\\"1\\""; mode "say \\"hi\\""
+workspace "\"1\"" output DP-0
+`, c.Generate())
+
+	assert.Equal(t, `mode "say \"hi\""`, Mode(`say "hi"`).String())
+}
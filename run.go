@@ -13,14 +13,19 @@ import (
 func (c *Config) Run() {
 	arg1 := ""
 
-	err := c.Validate()
-	if err != nil {
-		log.Fatal(err)
+	failed := false
+	for _, p := range c.Problems() {
+		log.Print(p)
+		failed = failed || !p.Warning
+	}
+	if failed {
+		os.Exit(1)
 	}
 
 	if len(os.Args) > 1 {
 		arg1 = os.Args[1]
 	}
+	c.applyChords()
 	if arg1 == "func" {
 		err := c.funcs[os.Args[2]]()
 		if err != nil {
@@ -29,8 +34,6 @@ func (c *Config) Run() {
 			os.Exit(1)
 		}
 	} else {
-		c.chords.apply(c)
-
 		dir := path.Dir(c.path)
 		err := exec.Command("go", "build", "-o", path.Join(dir, c.binName)).Run()
 		if err != nil {
@@ -45,6 +48,10 @@ func (c *Config) Run() {
 			if err != nil {
 				log.Fatal(err)
 			}
+			err = c.writeFiles()
+			if err != nil {
+				log.Fatal(err)
+			}
 		}
 	}
 }
@@ -0,0 +1,156 @@
+package i3config
+
+import (
+	"math"
+	"os"
+	"time"
+)
+
+// julianUnixEpoch is the Julian date of 1970-01-01 00:00 UTC and julian2000
+// the one of 2000-01-01 12:00 UTC.
+const (
+	julianUnixEpoch = 2440587.5
+	julian2000      = 2451545.0
+)
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianUnixEpoch
+}
+
+func fromJulian(j float64) time.Time {
+	return time.Unix(int64(math.Round((j-julianUnixEpoch)*86400)), 0)
+}
+
+func sinDeg(deg float64) float64 {
+	return math.Sin(deg * math.Pi / 180)
+}
+
+func cosDeg(deg float64) float64 {
+	return math.Cos(deg * math.Pi / 180)
+}
+
+// SunTimes returns sunrise and sunset on the day of t, in its location, at
+// latitude and longitude in degrees, north and east positive. They are
+// computed with the sunrise equation and are within a few minutes. During a
+// polar day the sun rises at midnight and sets at the next one, during a polar
+// night both are at noon.
+func SunTimes(t time.Time, latitude, longitude float64) (time.Time, time.Time) {
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	noon := midnight.Add(12 * time.Hour)
+
+	// mean solar noon, in days since 2000
+	n := math.Round(toJulian(noon) - julian2000 + longitude/360)
+	j := n - longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*j, 360)
+	center := 1.9148*sinDeg(anomaly) + 0.02*sinDeg(2*anomaly) + 0.0003*sinDeg(3*anomaly)
+	ecliptic := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := julian2000 + j + 0.0053*sinDeg(anomaly) - 0.0069*sinDeg(2*ecliptic)
+
+	declination := math.Asin(sinDeg(ecliptic) * sinDeg(23.4397))
+	cosHourAngle := (sinDeg(-0.833) - sinDeg(latitude)*math.Sin(declination)) /
+		(cosDeg(latitude) * math.Cos(declination))
+	switch {
+	case cosHourAngle < -1:
+		return midnight, midnight.AddDate(0, 0, 1)
+	case cosHourAngle > 1:
+		return noon, noon
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+	return fromJulian(transit - hourAngle/360).In(t.Location()),
+		fromJulian(transit + hourAngle/360).In(t.Location())
+}
+
+// ThemeSchedule picks the day theme between the start and the end of the day
+// and the night theme otherwise.
+type ThemeSchedule struct {
+	Day   Theme
+	Night Theme
+	// dayTimes returns when the day starts and ends on the day of t.
+	dayTimes func(t time.Time) (time.Time, time.Time)
+}
+
+// FixedThemeSchedule starts the day at dayStart and the night at nightStart,
+// both since midnight. A night start before the day start is fine for a day
+// that ends after midnight.
+func FixedThemeSchedule(day, night Theme, dayStart, nightStart time.Duration) *ThemeSchedule {
+	return &ThemeSchedule{
+		Day:   day,
+		Night: night,
+		dayTimes: func(t time.Time) (time.Time, time.Time) {
+			y, m, d := t.Date()
+			midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+			return midnight.Add(dayStart), midnight.Add(nightStart)
+		},
+	}
+}
+
+// SunThemeSchedule starts the day at sunrise and the night at sunset at
+// latitude and longitude, see SunTimes.
+func SunThemeSchedule(day, night Theme, latitude, longitude float64) *ThemeSchedule {
+	return &ThemeSchedule{
+		Day:   day,
+		Night: night,
+		dayTimes: func(t time.Time) (time.Time, time.Time) {
+			return SunTimes(t, latitude, longitude)
+		},
+	}
+}
+
+// IsDay reports if t is between the start and the end of the day.
+func (s *ThemeSchedule) IsDay(t time.Time) bool {
+	start, end := s.dayTimes(t)
+	if start.After(end) {
+		return !t.Before(start) || t.Before(end)
+	}
+	return !t.Before(start) && t.Before(end)
+}
+
+// Theme returns the day or night theme for t.
+func (s *ThemeSchedule) Theme(t time.Time) Theme {
+	if s.IsDay(t) {
+		return s.Day
+	}
+	return s.Night
+}
+
+// ThemeSchedule uses the theme of the schedule for the current time and
+// starts a helper with i3 that generates the config into configPath again and
+// reloads i3 when the schedule switches themes.
+func (c *Config) ThemeSchedule(s *ThemeSchedule, configPath string) {
+	c.Theme(s.Theme(time.Now()))
+	c.OnStartup(c.ExecFunc(func() error {
+		return c.switchThemes(s, configPath)
+	}).NoStartupID())
+}
+
+// switchThemes checks the schedule every minute instead of sleeping until the
+// next switch, the monotonic clock used by sleep stops while suspended. Errors
+// are shown as a notification and the switch is tried again at the next one.
+func (c *Config) switchThemes(s *ThemeSchedule, configPath string) error {
+	// the config may be older than the last switch when i3 starts
+	day := !s.IsDay(time.Now())
+	if info, err := os.Stat(configPath); err == nil {
+		day = s.IsDay(info.ModTime())
+	}
+	check := func() {
+		now := time.Now()
+		if s.IsDay(now) == day {
+			return
+		}
+		day = s.IsDay(now)
+		err := c.regenerate(configPath)
+		if err == nil {
+			err = I3msg(Reload)
+		}
+		if err != nil {
+			notify("i3config", "i3 config theme switch error", err.Error(), "")
+		}
+	}
+
+	check()
+	for range time.Tick(time.Minute) {
+		check()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package i3config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSunTimes(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		day       time.Time
+		latitude  float64
+		longitude float64
+		sunrise   time.Time
+		sunset    time.Time
+	}{
+		{
+			name:      "london summer",
+			day:       time.Date(2024, 6, 21, 15, 0, 0, 0, london),
+			latitude:  51.5074,
+			longitude: -0.1278,
+			sunrise:   time.Date(2024, 6, 21, 4, 43, 0, 0, london),
+			sunset:    time.Date(2024, 6, 21, 21, 21, 0, 0, london),
+		},
+		{
+			name:      "london winter",
+			day:       time.Date(2024, 12, 21, 1, 0, 0, 0, london),
+			latitude:  51.5074,
+			longitude: -0.1278,
+			sunrise:   time.Date(2024, 12, 21, 8, 4, 0, 0, london),
+			sunset:    time.Date(2024, 12, 21, 15, 54, 0, 0, london),
+		},
+		{
+			name:    "equinox",
+			day:     time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2024, 3, 20, 6, 4, 0, 0, time.UTC),
+			sunset:  time.Date(2024, 3, 20, 18, 11, 0, 0, time.UTC),
+		},
+		{
+			name:      "polar day",
+			day:       time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC),
+			latitude:  69.65,
+			longitude: 18.96,
+			sunrise:   time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC),
+			sunset:    time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "polar night",
+			day:       time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC),
+			latitude:  69.65,
+			longitude: 18.96,
+			sunrise:   time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC),
+			sunset:    time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sunrise, sunset := SunTimes(tc.day, tc.latitude, tc.longitude)
+			assert.WithinDuration(t, tc.sunrise, sunrise, 3*time.Minute)
+			assert.WithinDuration(t, tc.sunset, sunset, 3*time.Minute)
+		})
+	}
+}
+
+func TestThemeSchedule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 6, 21, hour, minute, 0, 0, time.UTC)
+	}
+	fixed := FixedThemeSchedule(SolarizedLightTheme, NordTheme, 7*time.Hour, 19*time.Hour+30*time.Minute)
+	late := FixedThemeSchedule(SolarizedLightTheme, NordTheme, 9*time.Hour, 1*time.Hour)
+	sun := SunThemeSchedule(SolarizedLightTheme, NordTheme, 0, 0)
+	testCases := []struct {
+		name     string
+		schedule *ThemeSchedule
+		time     time.Time
+		theme    string
+	}{
+		{name: "fixed morning", schedule: fixed, time: at(6, 59), theme: "nord"},
+		{name: "fixed day start", schedule: fixed, time: at(7, 0), theme: "solarized-light"},
+		{name: "fixed evening", schedule: fixed, time: at(19, 29), theme: "solarized-light"},
+		{name: "fixed night start", schedule: fixed, time: at(19, 30), theme: "nord"},
+		{name: "late after midnight", schedule: late, time: at(0, 30), theme: "solarized-light"},
+		{name: "late night", schedule: late, time: at(1, 30), theme: "nord"},
+		{name: "late day", schedule: late, time: at(23, 0), theme: "solarized-light"},
+		{name: "sun before sunrise", schedule: sun, time: at(5, 30), theme: "nord"},
+		{name: "sun noon", schedule: sun, time: at(12, 0), theme: "solarized-light"},
+		{name: "sun after sunset", schedule: sun, time: at(18, 30), theme: "nord"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.theme, tc.schedule.Theme(tc.time).Name)
+		})
+	}
+}
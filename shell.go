@@ -0,0 +1,405 @@
+package i3config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// shellWord is a single word of a shell command after quote removal and
+// expansion. dynamic explains why the value can only be known when the
+// command runs, e.g. because it contains a command substitution. src is the
+// word as written.
+type shellWord struct {
+	value   string
+	src     string
+	quoted  bool
+	dynamic string
+}
+
+type shellCommand struct {
+	words []*shellWord
+}
+
+// shellScript is the result of parsing an exec string the way /bin/sh would.
+// Pipelines, lists and background jobs are flattened into commands, and the
+// source of every command substitution is kept so it can be checked as well.
+type shellScript struct {
+	commands      []*shellCommand
+	substitutions []string
+}
+
+type shellParser struct {
+	src       []rune
+	pos       int
+	script    *shellScript
+	lookupEnv func(string) (string, bool)
+}
+
+func parseShell(src string) (*shellScript, error) {
+	p := &shellParser{
+		src:       []rune(src),
+		script:    &shellScript{},
+		lookupEnv: os.LookupEnv,
+	}
+	err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return p.script, nil
+}
+
+func (p *shellParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *shellParser) peek() rune {
+	return p.src[p.pos]
+}
+
+func (p *shellParser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	return r
+}
+
+func isShellOperator(r rune) bool {
+	return strings.ContainsRune("\n;|&<>()", r)
+}
+
+func (p *shellParser) parse() error {
+	cmd := &shellCommand{}
+	redirect := false
+	endCommand := func() {
+		if len(cmd.words) > 0 {
+			p.script.commands = append(p.script.commands, cmd)
+		}
+		cmd = &shellCommand{}
+	}
+
+	for {
+		for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+			p.pos++
+		}
+		if p.eof() {
+			break
+		}
+
+		r := p.peek()
+		switch {
+		case r == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+			continue
+		case r == '<' || r == '>' || (r == '&' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '>'):
+			if redirect {
+				return fmt.Errorf("unexpected %q", r)
+			}
+			redirect = p.redirect()
+			continue
+		case isShellOperator(r):
+			if redirect {
+				return fmt.Errorf("unexpected %q", r)
+			}
+			p.pos++
+			endCommand()
+			continue
+		}
+
+		wordStart := p.pos
+		word, err := p.word()
+		if err != nil {
+			return err
+		}
+		if !p.eof() && (p.peek() == '<' || p.peek() == '>') && isFileDescriptor(p.src[wordStart:p.pos]) {
+			// 2>file, the number is part of the redirect
+			continue
+		}
+		if redirect {
+			redirect = false
+			continue
+		}
+		cmd.words = append(cmd.words, word)
+	}
+	if redirect {
+		return fmt.Errorf("missing redirect target")
+	}
+	endCommand()
+	return nil
+}
+
+func isFileDescriptor(src []rune) bool {
+	if len(src) == 0 {
+		return false
+	}
+	for _, r := range src {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// redirect consumes a redirect operator and reports whether it is followed by
+// a target word.
+func (p *shellParser) redirect() bool {
+	p.next()
+	if !p.eof() && strings.ContainsRune("<>|", p.peek()) {
+		p.pos++
+	}
+	if !p.eof() && p.peek() == '&' {
+		p.pos++
+		for !p.eof() && (unicode.IsDigit(p.peek()) || p.peek() == '-') {
+			p.pos++
+		}
+		return false
+	}
+	return true
+}
+
+func (p *shellParser) word() (*shellWord, error) {
+	w := &shellWord{}
+	b := &strings.Builder{}
+	start := p.pos
+
+	for !p.eof() {
+		r := p.peek()
+		if r == ' ' || r == '\t' || isShellOperator(r) {
+			break
+		}
+		p.pos++
+
+		switch r {
+		case '\\':
+			w.quoted = true
+			if p.eof() {
+				break
+			}
+			escaped := p.next()
+			if escaped != '\n' {
+				b.WriteRune(escaped)
+			}
+		case '\'':
+			w.quoted = true
+			end := p.index('\'')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			b.WriteString(string(p.src[p.pos:end]))
+			p.pos = end + 1
+		case '"':
+			w.quoted = true
+			err := p.doubleQuoted(w, b)
+			if err != nil {
+				return nil, err
+			}
+		case '$':
+			err := p.expansion(w, b)
+			if err != nil {
+				return nil, err
+			}
+		case '`':
+			err := p.backtick(w)
+			if err != nil {
+				return nil, err
+			}
+		case '~':
+			if p.pos-1 == start && (p.eof() || p.peek() == '/' || p.peek() == ' ' || isShellOperator(p.peek())) {
+				p.variable(w, b, "HOME")
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	w.value = b.String()
+	w.src = string(p.src[start:p.pos])
+	return w, nil
+}
+
+func (p *shellParser) index(r rune) int {
+	for i := p.pos; i < len(p.src); i++ {
+		if p.src[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *shellParser) doubleQuoted(w *shellWord, b *strings.Builder) error {
+	for !p.eof() {
+		r := p.next()
+		switch r {
+		case '"':
+			return nil
+		case '\\':
+			if p.eof() {
+				return fmt.Errorf("unterminated double quote")
+			}
+			escaped := p.next()
+			switch escaped {
+			case '$', '`', '"', '\\':
+				b.WriteRune(escaped)
+			case '\n':
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(escaped)
+			}
+		case '$':
+			err := p.expansion(w, b)
+			if err != nil {
+				return err
+			}
+		case '`':
+			err := p.backtick(w)
+			if err != nil {
+				return err
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return fmt.Errorf("unterminated double quote")
+}
+
+func isNameRune(r rune, first bool) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		return true
+	}
+	return !first && r >= '0' && r <= '9'
+}
+
+// expansion handles everything after an unquoted or double quoted $.
+func (p *shellParser) expansion(w *shellWord, b *strings.Builder) error {
+	if p.eof() {
+		b.WriteRune('$')
+		return nil
+	}
+	r := p.peek()
+	switch {
+	case r == '(':
+		p.pos++
+		if !p.eof() && p.peek() == '(' {
+			p.pos++
+			_, err := p.balanced()
+			if err != nil {
+				return err
+			}
+			if p.eof() || p.next() != ')' {
+				return fmt.Errorf("unterminated arithmetic expansion")
+			}
+			w.dynamic = "arithmetic expansion"
+			return nil
+		}
+		sub, err := p.balanced()
+		if err != nil {
+			return err
+		}
+		p.script.substitutions = append(p.script.substitutions, sub)
+		w.dynamic = "command substitution $(" + sub + ")"
+	case r == '{':
+		p.pos++
+		end := p.index('}')
+		if end == -1 {
+			return fmt.Errorf("unterminated ${")
+		}
+		expr := string(p.src[p.pos:end])
+		p.pos = end + 1
+
+		name := expr
+		i := strings.IndexFunc(expr, func(r rune) bool { return !isNameRune(r, false) })
+		if i != -1 {
+			name = expr[:i]
+		}
+		op := expr[len(name):]
+		switch {
+		case op == "":
+			p.variable(w, b, name)
+		case strings.HasPrefix(op, ":-") || strings.HasPrefix(op, "-"):
+			if value, ok := p.lookupEnv(name); ok && (value != "" || op[0] == '-') {
+				b.WriteString(value)
+			} else {
+				b.WriteString(strings.TrimPrefix(strings.TrimPrefix(op, ":"), "-"))
+			}
+		default:
+			w.dynamic = "${" + expr + "}"
+		}
+	case isNameRune(r, true):
+		start := p.pos
+		for !p.eof() && isNameRune(p.peek(), false) {
+			p.pos++
+		}
+		p.variable(w, b, string(p.src[start:p.pos]))
+	case unicode.IsDigit(r) || strings.ContainsRune("@*#?$!-", r):
+		p.pos++
+		w.dynamic = "$" + string(r)
+	default:
+		b.WriteRune('$')
+	}
+	return nil
+}
+
+func (p *shellParser) variable(w *shellWord, b *strings.Builder, name string) {
+	value, ok := p.lookupEnv(name)
+	if !ok {
+		w.dynamic = "$" + name + " is not set"
+		return
+	}
+	b.WriteString(value)
+}
+
+func (p *shellParser) backtick(w *shellWord) error {
+	end := p.index('`')
+	if end == -1 {
+		return fmt.Errorf("unterminated backtick")
+	}
+	sub := string(p.src[p.pos:end])
+	p.pos = end + 1
+	p.script.substitutions = append(p.script.substitutions, sub)
+	w.dynamic = "command substitution `" + sub + "`"
+	return nil
+}
+
+// balanced reads up to the parenthesis closing one that has already been
+// consumed, skipping over quoted text.
+func (p *shellParser) balanced() (string, error) {
+	start := p.pos
+	depth := 1
+	for !p.eof() {
+		r := p.next()
+		switch r {
+		case '\\':
+			if !p.eof() {
+				p.pos++
+			}
+		case '\'':
+			end := p.index('\'')
+			if end == -1 {
+				return "", fmt.Errorf("unterminated single quote")
+			}
+			p.pos = end + 1
+		case '"':
+			for !p.eof() && p.peek() != '"' {
+				if p.next() == '\\' && !p.eof() {
+					p.pos++
+				}
+			}
+			if p.eof() {
+				return "", fmt.Errorf("unterminated double quote")
+			}
+			p.pos++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(p.src[start : p.pos-1]), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated $(")
+}
@@ -0,0 +1,104 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commandNames(s *shellScript) [][]string {
+	result := [][]string{}
+	for _, cmd := range s.commands {
+		words := []string{}
+		for _, w := range cmd.words {
+			words = append(words, w.value)
+		}
+		result = append(result, words)
+	}
+	return result
+}
+
+func TestParseShell(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("EMPTY", "")
+
+	testCases := []struct {
+		src           string
+		expected      [][]string
+		substitutions []string
+	}{
+		{
+			src:      "nm-applet &",
+			expected: [][]string{{"nm-applet"}},
+		},
+		{
+			src:      `"Google Chrome" --new-window`,
+			expected: [][]string{{"Google Chrome", "--new-window"}},
+		},
+		{
+			src:      "cat ~/.config/adam/bookmarks | sort | rofi -dmenu -i -p sites | xargs -r surf",
+			expected: [][]string{{"cat", "/home/test/.config/adam/bookmarks"}, {"sort"}, {"rofi", "-dmenu", "-i", "-p", "sites"}, {"xargs", "-r", "surf"}},
+		},
+		{
+			src:           `find ~/a -type f > ~/b && feh --bg-fill "$(cat ~/b)"`,
+			expected:      [][]string{{"find", "/home/test/a", "-type", "f"}, {"feh", "--bg-fill", ""}},
+			substitutions: []string{"cat ~/b"},
+		},
+		{
+			src:      `listAudioOutputs > ~/lao.log 2>&1`,
+			expected: [][]string{{"listAudioOutputs"}},
+		},
+		{
+			src:      `FOO=bar $HOME/bin/x '$HOME' "${EMPTY:-def}" a\ b`,
+			expected: [][]string{{"FOO=bar", "/home/test/bin/x", "$HOME", "def", "a b"}},
+		},
+		{
+			src:      `osascript -e 'tell application "Spotify" to playpause'`,
+			expected: [][]string{{"osascript", "-e", `tell application "Spotify" to playpause`}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			s, err := parseShell(tc.src)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, commandNames(s))
+			assert.Equal(t, tc.substitutions, s.substitutions)
+		})
+	}
+}
+
+func TestParseShellErrors(t *testing.T) {
+	for _, src := range []string{`echo "a`, `echo 'a`, `echo $(a`, `echo >`} {
+		t.Run(src, func(t *testing.T) {
+			_, err := parseShell(src)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateShellCommands(t *testing.T) {
+	c := New("config.go")
+	c.BindSym("a", Exec("sh -c 'true && i3config-missing-a | cat'"))
+	c.BindSym("b", Exec("env FOO=1 i3config-missing-b"))
+	c.BindSym("c", Exec("$I3CONFIG_UNSET_VAR --flag"))
+	c.BindSym("d", Exec(`i3config-missing-feh "$(i3config-missing-d)"`))
+	c.BindSym("e", Exec("nice -n 10 i3config-missing-e"))
+	c.BindSym("f", Exec("env -u DISPLAY -i FOO=1 BAR=2 i3config-missing-f --flag"))
+	c.BindSym("g", Exec("env -- i3config-missing-g"))
+
+	messages := []string{}
+	for _, p := range c.Problems() {
+		messages = append(messages, p.Message)
+	}
+	assert.Equal(t, []string{
+		"missing application i3config-missing-a",
+		"missing application i3config-missing-b",
+		`cannot check command $I3CONFIG_UNSET_VAR: $I3CONFIG_UNSET_VAR is not set`,
+		"missing application i3config-missing-feh",
+		"missing application i3config-missing-d",
+		"missing application i3config-missing-e",
+		"missing application i3config-missing-f",
+		"missing application i3config-missing-g",
+	}, messages)
+}
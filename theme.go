@@ -0,0 +1,162 @@
+package i3config
+
+// Theme describes the colors of a config by role. Client and bar colors are
+// derived from it so they always match.
+type Theme struct {
+	Name       string
+	Background Color
+	Foreground Color
+	// Accent marks the focused window and workspace.
+	Accent Color
+	Urgent Color
+	// Inactive is the background of the focused window on an unfocused
+	// output.
+	Inactive Color
+
+	// Font is used for window titles and BarFont for bars, it defaults to
+	// Font. Empty fonts are left out.
+	Font    string
+	BarFont string
+}
+
+var (
+	NordTheme = Theme{
+		Name:       "nord",
+		Background: "#2e3440",
+		Foreground: "#d8dee9",
+		Accent:     "#81a1c1",
+		Urgent:     "#bf616a",
+		Inactive:   "#3b4252",
+	}
+	SolarizedDarkTheme = Theme{
+		Name:       "solarized-dark",
+		Background: "#002b36",
+		Foreground: "#839496",
+		Accent:     "#268bd2",
+		Urgent:     "#dc322f",
+		Inactive:   "#073642",
+	}
+	SolarizedLightTheme = Theme{
+		Name:       "solarized-light",
+		Background: "#fdf6e3",
+		Foreground: "#657b83",
+		Accent:     "#268bd2",
+		Urgent:     "#dc322f",
+		Inactive:   "#eee8d5",
+	}
+	GruvboxDarkTheme = Theme{
+		Name:       "gruvbox-dark",
+		Background: "#282828",
+		Foreground: "#ebdbb2",
+		Accent:     "#458588",
+		Urgent:     "#cc241d",
+		Inactive:   "#3c3836",
+	}
+	DraculaTheme = Theme{
+		Name:       "dracula",
+		Background: "#282a36",
+		Foreground: "#f8f8f2",
+		Accent:     "#bd93f9",
+		Urgent:     "#ff5555",
+		Inactive:   "#44475a",
+	}
+)
+
+// dim is the foreground used for text that is not focused.
+func (t Theme) dim() Color {
+	return t.Foreground.Mix(t.Background, 0.4)
+}
+
+func (t Theme) ColorConfig() *ColorConfig {
+	return &ColorConfig{
+		Focused: ColorClass{
+			Border:      t.Accent,
+			Background:  t.Accent,
+			Text:        t.Background,
+			Indicator:   t.Accent.Lighten(0.15),
+			ChildBorder: t.Accent,
+		},
+		FocusedInactive: ColorClass{
+			Border:      t.Inactive,
+			Background:  t.Inactive,
+			Text:        t.Foreground,
+			Indicator:   t.Inactive,
+			ChildBorder: t.Inactive,
+		},
+		Unfocused: ColorClass{
+			Border:      t.Background,
+			Background:  t.Background,
+			Text:        t.dim(),
+			Indicator:   t.Background,
+			ChildBorder: t.Background,
+		},
+		Urgent: ColorClass{
+			Border:      t.Urgent,
+			Background:  t.Urgent,
+			Text:        t.Background,
+			Indicator:   t.Urgent,
+			ChildBorder: t.Urgent,
+		},
+		Placeholder: ColorClass{
+			Border:      t.Background,
+			Background:  t.Background,
+			Text:        t.Foreground,
+			Indicator:   t.Background,
+			ChildBorder: t.Background,
+		},
+		Background: t.Background,
+	}
+}
+
+func (t Theme) BarColorConfig() *BarColorConfig {
+	return &BarColorConfig{
+		Background: t.Background,
+		StatusLine: t.Foreground,
+		Separator:  t.dim(),
+		FocusedWorkspace: &BarWorkspaceColor{
+			Border:     t.Accent,
+			Background: t.Accent,
+			Text:       t.Background,
+		},
+		ActiveWorkspace: &BarWorkspaceColor{
+			Border:     t.Accent,
+			Background: t.Background,
+			Text:       t.Foreground,
+		},
+		InactiveWorkspace: &BarWorkspaceColor{
+			Border:     t.Background,
+			Background: t.Background,
+			Text:       t.dim(),
+		},
+		UrgentWorkspace: &BarWorkspaceColor{
+			Border:     t.Urgent,
+			Background: t.Urgent,
+			Text:       t.Background,
+		},
+	}
+}
+
+func (t Theme) barFont() string {
+	if t.BarFont != "" {
+		return t.BarFont
+	}
+	return t.Font
+}
+
+// Theme sets the client colors and font from t. Bars that do not set their
+// own colors or font use the theme's.
+func (c *Config) Theme(t Theme) {
+	c.setTheme(t)
+}
+
+// setTheme returns the client colors it adds, so they can be changed with
+// the theme later.
+func (c *Config) setTheme(t Theme) *ColorConfig {
+	c.theme = &t
+	colors := t.ColorConfig()
+	c.Colors(colors)
+	if t.Font != "" {
+		c.Font(t.Font)
+	}
+	return colors
+}
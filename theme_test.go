@@ -0,0 +1,81 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTheme(t *testing.T) {
+	c := New("config.go")
+	c.Bar(func(bc *BarConfig) {
+		bc.Position(Top)
+	})
+	theme := NordTheme
+	theme.Font = "pango:DejaVu Sans Mono 11"
+	c.Theme(theme)
+
+	assert.Equal(t, `bar {
+    position top
+    font pango:DejaVu Sans Mono 11
+    colors {
+        background #2e3440
+        statusline #d8dee9
+        separator #949aa5
+        focused_workspace #81a1c1 #81a1c1 #2e3440
+        active_workspace #81a1c1 #2e3440 #d8dee9
+        inactive_workspace #2e3440 #2e3440 #949aa5
+        urgent_workspace #bf616a #bf616a #2e3440
+    }
+    `+`
+}
+client.focused #81a1c1 #81a1c1 #2e3440 #b4c7da #81a1c1
+client.focused_inactive #3b4252 #3b4252 #d8dee9 #3b4252 #3b4252
+client.unfocused #2e3440 #2e3440 #949aa5 #2e3440 #2e3440
+client.urgent #bf616a #bf616a #2e3440 #bf616a #bf616a
+client.placeholder #2e3440 #2e3440 #d8dee9 #2e3440 #2e3440
+client.background #2e3440
+font pango:DejaVu Sans Mono 11
+`, c.Generate())
+}
+
+func TestThemeBarOverride(t *testing.T) {
+	c := New("config.go")
+	theme := DraculaTheme
+	theme.Font = "pango:Fira Sans 10"
+	theme.BarFont = "pango:Fira Mono 9"
+	c.Theme(theme)
+	c.Bar(func(bc *BarConfig) {
+		bc.Colors(&BarColorConfig{Background: "#000000"})
+	})
+	c.Bar(func(bc *BarConfig) {
+		bc.Font("pango:Fira Mono 12")
+	})
+
+	src := c.Generate()
+	assert.Contains(t, src, `bar {
+    colors {
+        background #000000
+    }
+    font pango:Fira Mono 9
+    `+`
+}`)
+	assert.Contains(t, src, `bar {
+    font pango:Fira Mono 12
+    colors {
+        background #282a36
+`)
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, theme := range []Theme{NordTheme, SolarizedDarkTheme, SolarizedLightTheme, GruvboxDarkTheme, DraculaTheme} {
+		t.Run(theme.Name, func(t *testing.T) {
+			c := New("config.go")
+			c.Theme(theme)
+			c.Bar(func(bc *BarConfig) {
+				bc.Colors(theme.BarColorConfig())
+			})
+			assert.Empty(t, c.Problems())
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package i3config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BindingUsage counts how often a binding ran.
+type BindingUsage struct {
+	Count int       `json:"count"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// UsageLog holds the usage of each binding, keyed by mode and normalized
+// keys like "default: Shift+Mod4+q".
+type UsageLog map[string]*BindingUsage
+
+func usageKey(mode string, keys KeyCombo) string {
+	return mode + ": " + keys.Normalize().String()
+}
+
+// LoadUsageLog reads a usage log written by RecordUsage. A missing file is an
+// empty log.
+func LoadUsageLog(file string) (UsageLog, error) {
+	usage := UsageLog{}
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return usage, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// Save writes the log to a temporary file first so a reader never sees half
+// of it.
+func (u UsageLog) Save(file string) error {
+	b, err := json.MarshalIndent(u, "", "    ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	err = os.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func (u UsageLog) record(key string, t time.Time) {
+	usage, ok := u[key]
+	if !ok {
+		usage = &BindingUsage{First: t}
+		u[key] = usage
+	}
+	usage.Count++
+	usage.Last = t
+}
+
+// bindingEventKeys returns the keys of the binding that ran, in the form
+// used by usageKey.
+func bindingEventKeys(b *I3msgBinding) KeyCombo {
+	keys := KeyCombo{Modifiers: []Modifier{}, Key: fmt.Sprint(b.InputCode)}
+	if b.Symbol != nil {
+		keys.Key = *b.Symbol
+	}
+	for _, name := range b.EventStateMask {
+		mod, ok := lookupModifier(name)
+		if !ok {
+			mod = Modifier(name)
+		}
+		keys.Modifiers = append(keys.Modifiers, mod)
+	}
+	keys.sort()
+	return keys
+}
+
+// RecordUsage starts a helper with i3 that counts every binding that runs in
+// file, so UsageReport can tell which bindings are used.
+func (c *Config) RecordUsage(file string) {
+	c.OnStartup(c.ExecFunc(func() error {
+		return recordUsage(file)
+	}).NoStartupID())
+}
+
+func recordUsage(file string) error {
+	usage, err := LoadUsageLog(file)
+	if err != nil {
+		return err
+	}
+
+	r := newUsageRecorder()
+	return subscribeForever([]string{"mode", "binding"}, func(e *I3msgEvent) error {
+		key := r.key(e)
+		if key == "" {
+			return nil
+		}
+		usage.record(key, time.Now())
+		return usage.Save(file)
+	})
+}
+
+// usageRecorder tracks the mode bindings are pressed in. i3 sends the binding
+// event after the command ran, so the mode event of a binding that switches
+// modes comes first and the binding was pressed in the mode before it.
+type usageRecorder struct {
+	mode     string
+	previous string
+}
+
+// newUsageRecorder starts in the default mode, i3 does not send the current
+// mode.
+func newUsageRecorder() *usageRecorder {
+	return &usageRecorder{
+		mode:     "default",
+		previous: "default",
+	}
+}
+
+// key returns the usage key of a binding that ran, or "" for other events.
+func (r *usageRecorder) key(e *I3msgEvent) string {
+	if e.Mode != nil {
+		r.previous, r.mode = r.mode, e.Mode.Change
+		return ""
+	}
+	if e.Binding == nil || e.Binding.Change != "run" {
+		return ""
+	}
+	mode := r.mode
+	if switchesMode(e.Binding.Binding.Command) {
+		mode = r.previous
+	}
+	return usageKey(mode, bindingEventKeys(e.Binding.Binding))
+}
+
+func switchesMode(command string) bool {
+	cmds, err := ParseCommand(command)
+	if err != nil {
+		return false
+	}
+	for _, cmd := range cmds {
+		if _, ok := cmd.ModeName(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// UsageReportEntry is a binding from the config with its usage.
+type UsageReportEntry struct {
+	*CheatSheetEntry
+	BindingUsage
+}
+
+// UsageReport sorts the bindings of a config by how often they ran.
+type UsageReport struct {
+	Unused   []*UsageReportEntry
+	Rare     []*UsageReportEntry
+	MostUsed []*UsageReportEntry
+}
+
+// UsageReport matches the usage log to the bindings of the config. The top
+// most run bindings are most used and the other bindings that ran at most
+// rare times are rarely used.
+func (c *Config) UsageReport(usage UsageLog, rare, top int) *UsageReport {
+	vars := c.variables()
+	report := &UsageReport{
+		Unused:   []*UsageReportEntry{},
+		Rare:     []*UsageReportEntry{},
+		MostUsed: []*UsageReportEntry{},
+	}
+	used := []*UsageReportEntry{}
+	for _, e := range c.CheatSheet() {
+		mode := e.Mode
+		if e.chord != "" {
+			mode = "Chord: " + e.chord
+		}
+		entry := &UsageReportEntry{CheatSheetEntry: e}
+		// i3 only sends the keycode of bindcode bindings
+		keys := e.Keys[len(e.Keys)-1]
+		if e.code != nil {
+			keys = *e.code
+		}
+		if u, ok := usage[usageKey(substituteVariables(mode, vars), keys)]; ok {
+			entry.BindingUsage = *u
+		}
+
+		if entry.Count == 0 {
+			report.Unused = append(report.Unused, entry)
+			continue
+		}
+		used = append(used, entry)
+	}
+
+	mostUsed := append([]*UsageReportEntry{}, used...)
+	sort.SliceStable(mostUsed, func(i, j int) bool {
+		return mostUsed[i].Count > mostUsed[j].Count
+	})
+	if len(mostUsed) > top {
+		mostUsed = mostUsed[:top]
+	}
+	report.MostUsed = mostUsed
+
+	// an entry is only listed once, most used first
+	isMostUsed := map[*UsageReportEntry]bool{}
+	for _, entry := range mostUsed {
+		isMostUsed[entry] = true
+	}
+	for _, entry := range used {
+		if entry.Count <= rare && !isMostUsed[entry] {
+			report.Rare = append(report.Rare, entry)
+		}
+	}
+	return report
+}
+
+func (r *UsageReport) String() string {
+	sections := []string{}
+	section := func(title string, entries []*UsageReportEntry) {
+		lines := []string{title + ":"}
+		for _, e := range entries {
+			line := fmt.Sprintf("  %-5d %s: %s  %s", e.Count, e.Mode, e.KeyString(), e.Description)
+			if e.Count > 0 {
+				line += ", last " + e.Last.Format("2006-01-02")
+			}
+			lines = append(lines, line)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	section("Most used", r.MostUsed)
+	section("Rarely used", r.Rare)
+	section("Never used", r.Unused)
+	return strings.Join(sections, "\n\n") + "\n"
+}
@@ -0,0 +1,174 @@
+package i3config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingEventKeys(t *testing.T) {
+	testCases := []struct {
+		event string
+		key   string
+	}{
+		{`{"change":"run","binding":{"command":"kill","event_state_mask":["shift","Mod4"],"input_code":0,"symbol":"Q","input_type":"keyboard"}}`, "default: Shift+Mod4+q"},
+		{`{"change":"run","binding":{"command":"focus left","event_state_mask":["ctrl"],"input_code":38,"symbol":null,"input_type":"keyboard"}}`, "default: Control+38"},
+		{`{"change":"run","binding":{"command":"floating toggle","event_state_mask":["Mod4"],"input_code":0,"symbol":"button3","input_type":"mouse"}}`, "default: Mod4+button3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			e := &I3msgEvent{}
+			require.NoError(t, json.Unmarshal([]byte(tc.event), e))
+			require.NotNil(t, e.Binding)
+			assert.Nil(t, e.Mode)
+			assert.Equal(t, tc.key, usageKey("default", bindingEventKeys(e.Binding.Binding)))
+		})
+	}
+
+	e := &I3msgEvent{}
+	require.NoError(t, json.Unmarshal([]byte(`{"change":"resize","pango_markup":false}`), e))
+	assert.Nil(t, e.Binding)
+	assert.Equal(t, "resize", e.Mode.Change)
+}
+
+func TestUsageRecorder(t *testing.T) {
+	binding := func(command, symbol string, mods ...string) string {
+		b, err := json.Marshal(map[string]interface{}{
+			"change": "run",
+			"binding": map[string]interface{}{
+				"command":          command,
+				"event_state_mask": mods,
+				"input_code":       0,
+				"symbol":           symbol,
+				"input_type":       "keyboard",
+			},
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+	mode := func(name string) string {
+		return `{"change":"` + name + `","pango_markup":false}`
+	}
+	// in the order i3 sends them, the mode event of a binding comes first
+	events := []string{
+		binding("kill", "q", "Mod4"),
+		mode("resize"),
+		binding(`mode "resize"`, "r", "Mod4"),
+		binding("resize shrink width 10 px or 10 ppt", "h"),
+		mode("default"),
+		binding(`mode "default"`, "Escape"),
+		mode("Chord: Mod4+o"),
+		binding(`mode "Chord: Mod4+o"`, "o", "Mod4"),
+		mode("default"),
+		binding(`mode "default"; reload`, "f"),
+	}
+
+	r := newUsageRecorder()
+	keys := []string{}
+	for _, event := range events {
+		e := &I3msgEvent{}
+		require.NoError(t, json.Unmarshal([]byte(event), e))
+		if key := r.key(e); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	assert.Equal(t, []string{
+		"default: Mod4+q",
+		"default: Mod4+r",
+		"resize: h",
+		"resize: Escape",
+		"default: Mod4+o",
+		"Chord: Mod4+o: f",
+	}, keys)
+}
+
+func TestUsageLogSave(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "i3config", "usage.json")
+	usage, err := LoadUsageLog(file)
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	usage.record("default: Mod4+q", first)
+	usage.record("default: Mod4+q", first.Add(time.Hour))
+	require.NoError(t, usage.Save(file))
+
+	loaded, err := LoadUsageLog(file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded["default: Mod4+q"].Count)
+	assert.True(t, first.Equal(loaded["default: Mod4+q"].First))
+	assert.True(t, first.Add(time.Hour).Equal(loaded["default: Mod4+q"].Last))
+}
+
+func TestUsageReport(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindSym("$mod+q", Kill).Describe("Close")
+	c.BindSym("$mod+Shift+Q", Restart)
+	c.BindSym("$mod+h", FocusLeft)
+	c.BindSym("$mod+r", Mode("resize"))
+	c.BindChord("$mod+Shift+o", "f", Reload).Describe("Reload")
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("Escape", Mode("default"))
+	})
+
+	last := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	// bindings that switch modes are logged under the mode they were
+	// pressed in, see TestUsageRecorder
+	usage := UsageLog{
+		"default: Mod4+q":             {Count: 40, Last: last},
+		"default: Mod4+r":             {Count: 3, Last: last},
+		"resize: Escape":              {Count: 3, Last: last},
+		"Chord: Mod4+Shift+o: f":      {Count: 1, Last: last},
+		"default: Shift+Mod4+button1": {Count: 9, Last: last},
+	}
+
+	report := c.UsageReport(usage, 2, 3)
+	assert.Equal(t, `Most used:
+  40    default: Mod4+q  Close, last 2024-03-01
+  3     default: Mod4+r  mode "resize", last 2024-03-01
+  3     resize: Escape  mode "default", last 2024-03-01
+
+Rarely used:
+  1     default: Shift+Mod4+o f  Reload, last 2024-03-01
+
+Never used:
+  0     default: Shift+Mod4+Q  restart
+  0     default: Mod4+h  focus left
+`, report.String())
+}
+
+func TestUsageReportKeycodes(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.Keymap(loadKeymap(t, "us"))
+	c.ToCode()
+	c.BindSym("$mod+q", Kill)
+	c.BindSym("$mod+h", FocusLeft)
+	c.BindChord("$mod+o", "f", Reload)
+
+	usage := UsageLog{}
+	record := func(mods []string, code int) {
+		keys := bindingEventKeys(&I3msgBinding{EventStateMask: mods, InputCode: code, InputType: "keyboard"})
+		usage.record(usageKey("default", keys), time.Now())
+	}
+	record([]string{"Mod4"}, 24)
+	record([]string{"Mod4"}, 24)
+	record([]string{"Mod4"}, 43)
+	usage.record(usageKey("Chord: Mod4+o", bindingEventKeys(&I3msgBinding{EventStateMask: []string{}, InputCode: 41})), time.Now())
+
+	report := c.UsageReport(usage, 2, 1)
+	assert.Len(t, report.Unused, 0)
+	require.Len(t, report.MostUsed, 1)
+	assert.Equal(t, "kill", report.MostUsed[0].Description)
+	rare := []string{}
+	for _, e := range report.Rare {
+		rare = append(rare, e.Description)
+	}
+	// most used bindings are not rare too
+	assert.Equal(t, []string{"focus left", "reload"}, rare)
+}
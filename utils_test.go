@@ -30,7 +30,7 @@ func TestEscapeString(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.str, func(t *testing.T) {
-			result := escapeString(tc.str)
+			result := configLine(quoteCommand(tc.str))
 			assert.Equal(t, tc.expected, result)
 		})
 	}
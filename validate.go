@@ -4,51 +4,339 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/abibby/salusa/extra/sets"
 )
 
+// Problem is a single issue found while validating a config. Warnings are
+// things that could not be checked and do not fail validation.
+type Problem struct {
+	Source  Source
+	Message string
+	Warning bool
+}
+
+func (p *Problem) String() string {
+	if p.Warning {
+		return p.Source.String() + ": warning: " + p.Message
+	}
+	return p.Source.String() + ": " + p.Message
+}
+
+// ValidationError holds every problem found by Validate.
+type ValidationError []*Problem
+
+func (e ValidationError) Error() string {
+	lines := make([]string, len(e))
+	for i, p := range e {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+type validator struct {
+	apps     map[string]error
+	problems []*Problem
+	// vars are the variables set in the config
+	vars map[string]string
+}
+
+func (v *validator) report(source Source, message string) {
+	v.problems = append(v.problems, &Problem{
+		Source:  source,
+		Message: message,
+	})
+}
+
+// problem records an invalid value passed to a Config method, it is reported
+// by Problems with the other errors.
+func (c *Config) problem(source Source, format string, args ...interface{}) {
+	c.problems = append(c.problems, &Problem{
+		Source:  source,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checker is implemented by lines that can check their own values, vars are
+// the variables set in the config.
+type checker interface {
+	check(vars map[string]string) []string
+}
+
+func (v *validator) warn(source Source, message string) {
+	v.problems = append(v.problems, &Problem{
+		Source:  source,
+		Message: message,
+		Warning: true,
+	})
+}
+
+// Problems walks the whole config, including modes, bars and chords, and
+// returns every error and warning it finds.
+func (c *Config) Problems() []*Problem {
+	v := &validator{
+		apps: map[string]error{},
+		vars: c.variables(),
+	}
+	c.validate(v)
+	c.lintBindings(v, "default")
+	c.ModeGraph().lint(v)
+	return v.problems
+}
+
+// Validate returns a ValidationError listing every problem found by Problems
+// that is not a warning.
 func (c *Config) Validate() error {
-	apps := sets.New[string]()
-	for _, line := range c.lines {
-		switch line := line.(type) {
-		case *Command:
-			app := getApplication(line)
-			if app != "" {
-				apps.Add(app)
+	errs := ValidationError{}
+	for _, p := range c.Problems() {
+		if !p.Warning {
+			errs = append(errs, p)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *Config) validate(v *validator) {
+	v.problems = append(v.problems, c.problems...)
+	for _, l := range c.lines {
+		if ch, ok := l.generator.(checker); ok {
+			for _, message := range ch.check(v.vars) {
+				v.report(l.source, message)
 			}
+		}
+		switch g := l.generator.(type) {
+		case *Command:
+			v.command(g, l.source)
 		case *Bind:
-			for _, c := range line.commands {
-				app := getApplication(c)
-				if app != "" {
-					apps.Add(app)
-				}
+			if len(g.commands) == 0 {
+				v.report(l.source, fmt.Sprintf("%s %s has no commands", g.bindType, g.keys))
+			}
+			v.commands(g.commands, l.source)
+		case *ForWindowType:
+			if len(g.commands) == 0 {
+				v.report(l.source, "for_window has no commands")
+			}
+			v.criteria(&g.criteria, l.source)
+			v.commands(g.commands, l.source)
+		case statusCommand:
+			v.exec(string(g), l.source)
+		case *ColorConfig:
+			c.contrast.check(v, g.contrastPairs(), l.source)
+		case *BarColorConfig:
+			c.contrast.check(v, g.contrastPairs(), l.source)
+		case *ModeType:
+			g.config.validate(v)
+		case *BarConfig:
+			g.Config.validate(v)
+			if colors := g.themeColors(); colors != nil {
+				c.contrast.check(v, colors.contrastPairs(), l.source)
+			}
+		}
+	}
+	for _, f := range c.files {
+		if f.themed && c.theme == nil {
+			v.report(f.source, "theme file "+f.path+" needs a theme")
+		}
+	}
+	for _, key1 := range c.chords.keys() {
+		for _, bound := range c.chords[key1] {
+			v.commands(bound.commands, bound.source)
+		}
+	}
+}
+
+func (v *validator) commands(commands []*Command, source Source) {
+	for _, cmd := range commands {
+		v.command(cmd, source)
+	}
+}
+
+var (
+	windowTypes      = sets.NewMapSet(Normal, Dialog, Utility, Toolbar, Splash, Menu, DropdownMenu, PopupMenu, Tooltip, Notification)
+	resizeDirections = sets.NewMapSet(Up, Down, Left, Right, Width, Height)
+	// newest, recent and last are aliases of latest, first of oldest
+	urgentValues = sets.NewMapSet(Latest, Oldest, "newest", "recent", "last", "first")
+)
+
+func (v *validator) criteria(c *Criteria, source Source) {
+	if c == nil {
+		return
+	}
+	if c.WindowType != "" && !windowTypes.Has(c.WindowType) {
+		v.report(source, fmt.Sprintf("unknown window_type %q", c.WindowType))
+	}
+	if c.Urgent != "" && !urgentValues.Has(c.Urgent) {
+		v.report(source, fmt.Sprintf("unknown urgent value %q", c.Urgent))
+	}
+}
+
+func (v *validator) command(c *Command, source Source) {
+	if c.err != nil {
+		v.report(source, fmt.Sprintf("invalid command: %v", c.err))
+		return
+	}
+	if strings.TrimSpace(c.kind) == "" {
+		v.report(source, "empty command")
+		return
+	}
+	v.criteria(c.criteria, source)
+	args := c.Args()
+	switch c.kind {
+	case "exec", "exec_always":
+		command, _ := c.ExecCommand()
+		if strings.TrimSpace(command) == "" {
+			v.report(source, c.kind+" has no command")
+			return
+		}
+		v.exec(command, source)
+	case "border":
+		if len(args) == 2 && (args[0] == "pixel" || args[0] == "normal") {
+			if n, err := strconv.Atoi(args[1]); err != nil || n < 0 {
+				v.report(source, fmt.Sprintf("invalid border width %q", args[1]))
+			}
+		}
+	case "resize":
+		if _, err := c.Resize(); err != nil {
+			v.report(source, err.Error())
+		}
+	}
+}
+
+func (v *validator) exec(command string, source Source) {
+	script, err := parseShell(command)
+	if err != nil {
+		v.warn(source, fmt.Sprintf("cannot check exec %q: %v", command, err))
+		return
+	}
+	for _, cmd := range script.commands {
+		v.shellCommand(cmd.words, source)
+	}
+	for _, sub := range script.substitutions {
+		v.exec(sub, source)
+	}
+}
+
+var (
+	shellKeywords = sets.NewMapSet("!", "{", "}", "if", "then", "elif", "else", "fi", "do", "done", "while", "until", "esac")
+	shellBuiltins = sets.NewMapSet(":", ".", "[", "alias", "break", "cd", "continue", "echo", "eval", "exit", "export", "false", "printf", "pwd", "read", "return", "set", "shift", "source", "test", "trap", "true", "type", "ulimit", "umask", "unset", "wait")
+	// shellWrappers run the command in their arguments, with the options of
+	// each that take a value
+	shellWrappers = map[string]sets.MapSet[string]{
+		"command": sets.NewMapSet[string](),
+		"env":     sets.NewMapSet("-u", "--unset", "-C", "--chdir", "-S", "--split-string"),
+		"exec":    sets.NewMapSet("-a"),
+		"nice":    sets.NewMapSet("-n", "--adjustment"),
+		"nohup":   sets.NewMapSet[string](),
+		"setsid":  sets.NewMapSet[string](),
+		"time":    sets.NewMapSet("-f", "--format", "-o", "--output"),
+	}
+	shells = sets.NewMapSet("sh", "bash", "dash", "ksh", "zsh")
+)
+
+func (v *validator) shellCommand(words []*shellWord, source Source) {
+	for len(words) > 0 && isAssignment(words[0]) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return
+	}
+
+	name := words[0]
+	if name.dynamic != "" {
+		v.warn(source, fmt.Sprintf("cannot check command %s: %s", name.src, name.dynamic))
+		return
+	}
+	args := words[1:]
+
+	switch {
+	case !name.quoted && shellKeywords.Has(name.value):
+		v.shellCommand(args, source)
+	case !name.quoted && (name.value == "for" || name.value == "case"):
+		// the words of the header are not commands, the body is parsed as
+		// separate commands
+	case shellBuiltins.Has(name.value):
+	case shellWrappers[name.value] != nil:
+		if name.value != "exec" && name.value != "command" {
+			v.app(name.value, source)
+		}
+		v.shellCommand(wrappedCommand(name.value, args), source)
+	case shells.Has(path.Base(name.value)):
+		v.app(name.value, source)
+		if script := shellScriptArg(args); script != nil {
+			if script.dynamic != "" {
+				v.warn(source, fmt.Sprintf("cannot check %s -c script: %s", name.value, script.dynamic))
+			} else {
+				v.exec(script.value, source)
 			}
 		}
+	default:
+		v.app(name.value, source)
+	}
+}
+
+// wrappedCommand skips the options of a wrapper and their values, and the
+// variables env sets, leaving the command it runs.
+func wrappedCommand(wrapper string, args []*shellWord) []*shellWord {
+	withValue := shellWrappers[wrapper]
+	for len(args) > 0 && strings.HasPrefix(args[0].value, "-") {
+		option := args[0].value
+		args = args[1:]
+		if option == "--" {
+			break
+		}
+		if withValue.Has(option) && len(args) > 0 {
+			args = args[1:]
+		}
 	}
+	if wrapper == "env" {
+		for len(args) > 0 && isAssignment(args[0]) {
+			args = args[1:]
+		}
+	}
+	return args
+}
 
-	missing := []string{}
-	for app := range apps.All() {
-		err := checkApp(app)
-		if err != nil {
-			missing = append(missing, app)
+func isAssignment(w *shellWord) bool {
+	i := strings.IndexRune(w.value, '=')
+	if i <= 0 {
+		return false
+	}
+	for j, r := range w.value[:i] {
+		if !isNameRune(r, j == 0) {
+			return false
 		}
 	}
+	return true
+}
 
-	if len(missing) > 0 {
-		return fmt.Errorf("missing applications: %s", strings.Join(missing, ", "))
+// shellScriptArg finds the script passed to a shell with -c.
+func shellScriptArg(args []*shellWord) *shellWord {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg.value, "-") || arg.value == "--" {
+			return nil
+		}
+		if strings.ContainsRune(arg.value[1:], 'c') && i+1 < len(args) {
+			return args[i+1]
+		}
 	}
 	return nil
 }
 
-func getApplication(c *Command) string {
-	if c.name != "exec" {
-		return ""
+func (v *validator) app(app string, source Source) {
+	err, ok := v.apps[app]
+	if !ok {
+		err = checkApp(app)
+		v.apps[app] = err
+	}
+	if err != nil {
+		v.report(source, "missing application "+app)
 	}
-	command := unescapeString(c.value)
-	parts := strings.SplitN(command, " ", 2)
-	return parts[0]
 }
 
 func checkApp(path string) error {
@@ -0,0 +1,149 @@
+package i3config
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestValidateReportsNestedProblems(t *testing.T) {
+	missing := Exec("i3config-missing-app --flag")
+
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.BindSym("$mod+a", missing)
+	bindLine := currentLine() - 1
+	c.Mode("resize", func(c *Config) {
+		c.BindSym("Escape", Mode("default"), missing)
+	})
+	modeLine := currentLine() - 2
+	c.Bar(func(bc *BarConfig) {
+		bc.StatusCommand("i3config-missing-app")
+	})
+	barLine := currentLine() - 2
+	c.ForWindow(Criteria{Class: "x"}, missing)
+	forWindowLine := currentLine() - 1
+	c.AlwaysOnStartup(Exec("i3config-missing-app"))
+	startupLine := currentLine() - 1
+	c.BindChord("$mod+b", "b", missing)
+	chordLine := currentLine() - 1
+
+	lines := func(err error) []int {
+		require.IsType(t, ValidationError{}, err)
+		result := []int{}
+		for _, p := range err.(ValidationError) {
+			assert.Equal(t, "missing application i3config-missing-app", p.Message)
+			assert.Contains(t, p.Source.File, "validate_test.go")
+			result = append(result, p.Source.Line)
+		}
+		return result
+	}
+
+	assert.Equal(t,
+		[]int{bindLine, modeLine, barLine, forWindowLine, startupLine, chordLine},
+		lines(c.Validate()),
+	)
+
+	c.applyChords()
+	assert.Equal(t,
+		[]int{bindLine, modeLine, barLine, forWindowLine, startupLine, chordLine},
+		lines(c.Validate()),
+	)
+}
+
+func TestValidateValues(t *testing.T) {
+	c := New("config.go")
+	c.Colors(&ColorConfig{
+		Focused:         ConstantColorClass(HexColor("88c0d0")),
+		FocusedInactive: ConstantColorClass(HexColor("2e3440")),
+		Unfocused:       ConstantColorClass(HexColor("2e3440")),
+		Urgent:          ConstantColorClass(HexColor("bf616a")),
+		Placeholder:     ConstantColorClass(HexColor("a3be8c")),
+		Background:      HexColor("2e344"),
+	})
+	colorsLine := currentLine() - 8
+	c.Bar(func(bc *BarConfig) {
+		bc.Position("middle")
+		bc.Colors(&BarColorConfig{
+			Background:       HexColor("2e3440"),
+			FocusedWorkspace: &BarWorkspaceColor{Border: "red", Background: HexColor("2e3440"), Text: HexColor("2e3440")},
+		})
+	})
+	barLine := currentLine() - 7
+	c.HideEdgeBorders("all")
+	c.Gaps(Gaps{Inner: -5})
+	c.Font("")
+	c.BindSym("Mod4+q")
+	c.BindSym("Mod4+w", Border(-1), ResizeGrow("sideways", 10), ResizeSet(Size{}), NewCommand("", ""))
+	c.ForWindow(Criteria{WindowType: "window"}, Kill)
+	c.BindSym("Mod4+e", Kill.For(Criteria{Urgent: "newer"}))
+	c.BindSym("Mod4+r", Kill.For(Criteria{Urgent: "newest"}), Kill.For(Criteria{Urgent: "first"}))
+	lastLine := currentLine() - 1
+
+	messages := []string{}
+	lines := []int{}
+	for _, p := range c.Problems() {
+		messages = append(messages, p.Message)
+		lines = append(lines, p.Source.Line)
+	}
+	assert.Equal(t, []string{
+		`unknown hide_edge_borders value "all"`,
+		"gaps must not be negative, got inner -5 and outer 0",
+		"empty font",
+		`invalid color "#2e344" for client.background`,
+		`unknown bar position "middle"`,
+		`invalid color "red" for bar focused_workspace border`,
+		"bindsym Mod4+q has no commands",
+		`invalid border width "-1"`,
+		"invalid resize grow sideways 10 px or 10 ppt",
+		"resize set needs a width or height",
+		"empty command",
+		`unknown window_type "window"`,
+		`unknown urgent value "newer"`,
+	}, messages)
+	assert.Equal(t, []int{
+		lastLine - 7, lastLine - 6, lastLine - 5,
+		colorsLine,
+		barLine + 1, barLine + 2,
+		lastLine - 4, lastLine - 3, lastLine - 3, lastLine - 3, lastLine - 3,
+		lastLine - 2, lastLine - 1,
+	}, lines)
+}
+
+func TestBuild(t *testing.T) {
+	c := New("config.go")
+	c.BindChord("Mod4+o", "f", Kill)
+	src, err := c.Build()
+	assert.NoError(t, err)
+	assert.Contains(t, src, "bindsym Mod4+o mode \"Chord: Mod4+o\"\n")
+
+	c.Colors(&ColorConfig{})
+	src, err = c.Build()
+	assert.Equal(t, "", src)
+	assert.Len(t, err.(ValidationError), 26)
+}
+
+func TestChordsSorted(t *testing.T) {
+	c := New("config.go")
+	c.BindChord("Mod4+z", "x", Border(-1))
+	zLine := currentLine() - 1
+	c.BindChord("Mod4+a", "x", Border(-2))
+	aLine := currentLine() - 1
+
+	lines := []int{}
+	for _, p := range c.Problems() {
+		lines = append(lines, p.Source.Line)
+	}
+	assert.Equal(t, []int{aLine, zLine}, lines)
+
+	src := c.Generate()
+	assert.Less(t, strings.Index(src, `mode "Chord: Mod4+a"`), strings.Index(src, `mode "Chord: Mod4+z"`))
+}
@@ -0,0 +1,302 @@
+package i3config
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxSamples limits how many pixels of an image are used to find its colors.
+const maxSamples = 1 << 16
+
+// colorBox is a group of pixels for median cut.
+type colorBox [][3]uint8
+
+// widest returns the channel with the widest range of values and its range.
+func (b colorBox) widest() (int, int) {
+	channel, width := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		min, max := 255, 0
+		for _, p := range b {
+			v := int(p[ch])
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if max-min > width {
+			channel, width = ch, max-min
+		}
+	}
+	return channel, width
+}
+
+// cut returns the index to split a box sorted by channel at. It is the
+// boundary between two values closest to the median, so pixels of the same
+// color stay together.
+func (b colorBox) cut(channel int) int {
+	mid := len(b) / 2
+	lo, hi := mid, mid
+	for lo > 0 && b[lo-1][channel] == b[mid][channel] {
+		lo--
+	}
+	for hi < len(b) && b[hi][channel] == b[mid][channel] {
+		hi++
+	}
+	if lo == 0 || (hi < len(b) && hi-mid < mid-lo) {
+		return hi
+	}
+	return lo
+}
+
+func (b colorBox) average() Color {
+	var r, g, bl int
+	for _, p := range b {
+		r += int(p[0])
+		g += int(p[1])
+		bl += int(p[2])
+	}
+	n := len(b)
+	return RGB(uint8((r+n/2)/n), uint8((g+n/2)/n), uint8((bl+n/2)/n))
+}
+
+// ExtractPalette finds up to n colors of the image with median cut, the most
+// common first. Transparent pixels are ignored.
+func ExtractPalette(img image.Image, n int) []Color {
+	bounds := img.Bounds()
+	step := int(math.Ceil(math.Sqrt(float64(bounds.Dx()*bounds.Dy()) / maxSamples)))
+	if step < 1 {
+		step = 1
+	}
+	pixels := colorBox{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			// un-premultiply the channels
+			pixels = append(pixels, [3]uint8{
+				uint8(r * 0xff / a),
+				uint8(g * 0xff / a),
+				uint8(b * 0xff / a),
+			})
+		}
+	}
+	if len(pixels) == 0 || n < 1 {
+		return []Color{}
+	}
+
+	boxes := []colorBox{pixels}
+	for len(boxes) < n {
+		split, splitChannel, splitWidth := -1, 0, 0
+		for i, b := range boxes {
+			channel, width := b.widest()
+			if len(b) > 1 && width > splitWidth {
+				split, splitChannel, splitWidth = i, channel, width
+			}
+		}
+		if split == -1 {
+			break
+		}
+		b := boxes[split]
+		sort.SliceStable(b, func(i, j int) bool {
+			return b[i][splitChannel] < b[j][splitChannel]
+		})
+		mid := b.cut(splitChannel)
+		boxes[split] = b[:mid]
+		boxes = append(boxes, b[mid:])
+	}
+
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return len(boxes[i]) > len(boxes[j])
+	})
+	colors := make([]Color, len(boxes))
+	for i, b := range boxes {
+		colors[i] = b.average()
+	}
+	return colors
+}
+
+// withContrast moves the lightness of c away from background until their
+// contrast is at least ratio, or c is black or white.
+func withContrast(c, background Color, ratio float64) Color {
+	step := 0.02
+	if background.Luminance() > 0.18 {
+		step = -step
+	}
+	for i := 0; i < 50 && c.Contrast(background) < ratio; i++ {
+		c = c.Lighten(step)
+	}
+	return c
+}
+
+// ImageTheme builds a theme from the colors of an image. The most common
+// color becomes the background, dark or light depending on the image, and the
+// most saturated one the accent. Text keeps a contrast of at least 4.5, 7 for
+// the foreground so dimmed text stays readable too.
+func ImageTheme(img image.Image) (Theme, error) {
+	colors := ExtractPalette(img, 8)
+	if len(colors) == 0 {
+		return Theme{}, fmt.Errorf("image has no opaque pixels")
+	}
+
+	h, s, l := colors[0].HSL()
+	light := l >= 0.5
+	background := HSL(h, math.Min(s, 0.5), math.Min(l, 0.15))
+	foreground := HSL(h, math.Min(s, 0.2), 0.85)
+	inactive := background.Lighten(0.08)
+	if light {
+		background = HSL(h, math.Min(s, 0.5), math.Max(l, 0.92))
+		foreground = HSL(h, math.Min(s, 0.2), 0.2)
+		inactive = background.Darken(0.08)
+	}
+
+	accent := colors[0]
+	for _, c := range colors {
+		if saturation(c) > saturation(accent) {
+			accent = c
+		}
+	}
+
+	urgent := HSL(0, 0.65, 0.5)
+	for _, c := range colors {
+		ch, cs, _ := c.HSL()
+		if cs > 0.4 && (ch < 15 || ch > 345) {
+			urgent = c
+			break
+		}
+	}
+
+	foreground = withContrast(withContrast(foreground, background, 7), inactive, 4.5)
+	return Theme{
+		Name:       "image",
+		Background: background,
+		Foreground: foreground,
+		Accent:     withContrast(accent, background, 4.5),
+		Urgent:     withContrast(urgent, background, 4.5),
+		Inactive:   inactive,
+	}, nil
+}
+
+func saturation(c Color) float64 {
+	_, s, _ := c.HSL()
+	return s
+}
+
+// LoadImageTheme builds a theme from a PNG or JPEG image.
+func LoadImageTheme(file string) (Theme, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Theme{}, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return Theme{}, fmt.Errorf("%s: %v", file, err)
+	}
+	return ImageTheme(img)
+}
+
+// wallpaperSource is where a wallpaper theme came from, so it can be read
+// again when the wallpaper changes.
+type wallpaperSource struct {
+	pathFile string
+	fallback Theme
+	colors   *ColorConfig
+}
+
+// WallpaperTheme uses a theme built from the wallpaper whose path is stored in
+// pathFile, like the one a wallpaper picker writes. The fonts come from
+// fallback, which is used as is when the wallpaper can not be read.
+// ApplyFunc reads the wallpaper again.
+func (c *Config) WallpaperTheme(pathFile string, fallback Theme) {
+	theme, err := wallpaperTheme(pathFile, fallback)
+	if err != nil {
+		c.problems = append(c.problems, &Problem{
+			Source:  callerSource(),
+			Message: fmt.Sprintf("using the %s theme: %v", fallback.Name, err),
+			Warning: true,
+		})
+		theme = fallback
+	}
+	c.wallpaper = &wallpaperSource{
+		pathFile: pathFile,
+		fallback: fallback,
+		colors:   c.setTheme(theme),
+	}
+}
+
+func wallpaperTheme(pathFile string, fallback Theme) (Theme, error) {
+	b, err := os.ReadFile(pathFile)
+	if err != nil {
+		return Theme{}, err
+	}
+	theme, err := LoadImageTheme(strings.TrimSpace(string(b)))
+	if err != nil {
+		return Theme{}, err
+	}
+	theme.Name = "wallpaper"
+	theme.Font = fallback.Font
+	theme.BarFont = fallback.BarFont
+	return theme, nil
+}
+
+// reloadWallpaper builds the wallpaper theme again from the current
+// wallpaper and uses it in place of the one read when the config was built.
+func (c *Config) reloadWallpaper() error {
+	if c.wallpaper == nil {
+		return nil
+	}
+	theme, err := wallpaperTheme(c.wallpaper.pathFile, c.wallpaper.fallback)
+	if err != nil {
+		return err
+	}
+	*c.theme = theme
+	*c.wallpaper.colors = *theme.ColorConfig()
+	return nil
+}
+
+// ApplyFunc generates the config into configPath, writes the files added with
+// File and ThemeFile and reloads i3, so a running i3 picks up changes like a
+// new wallpaper theme. The wallpaper is read again first, it has usually
+// changed since the config was built.
+func (c *Config) ApplyFunc(configPath string) error {
+	err := c.apply(configPath)
+	if err != nil {
+		return err
+	}
+	return I3msg(Reload)
+}
+
+func (c *Config) apply(configPath string) error {
+	err := c.reloadWallpaper()
+	if err != nil {
+		return err
+	}
+	src, err := c.Build()
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(configPath, []byte(src), 0644)
+	if err != nil {
+		return err
+	}
+	return c.writeFiles()
+}
+
+// Apply returns a command that runs ApplyFunc, e.g. to bind to a key after
+// picking a new wallpaper.
+func (c *Config) Apply(configPath string) *Command {
+	return c.ExecFunc(func() error {
+		return c.ApplyFunc(configPath)
+	})
+}
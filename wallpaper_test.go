@@ -0,0 +1,175 @@
+package i3config
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripes returns an image with a vertical stripe for each color, the width
+// of each stripe is its share of the image.
+func stripes(colors []Color, widths []int) image.Image {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	img := image.NewRGBA(image.Rect(0, 0, total, 10))
+	x := 0
+	for i, c := range colors {
+		r, g, b, a := c.RGBA()
+		for ; x < total && x < sum(widths[:i+1]); x++ {
+			for y := 0; y < 10; y++ {
+				img.Set(x, y, color.RGBA{r, g, b, a})
+			}
+		}
+	}
+	return img
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func TestExtractPalette(t *testing.T) {
+	testCases := []struct {
+		name   string
+		img    image.Image
+		n      int
+		colors []Color
+	}{
+		{
+			name:   "single",
+			img:    stripes([]Color{"#2e3440"}, []int{10}),
+			n:      4,
+			colors: []Color{"#2e3440"},
+		},
+		{
+			name:   "by population",
+			img:    stripes([]Color{"#ff0000", "#0000ff", "#00ff00", "#000000"}, []int{10, 40, 20, 30}),
+			n:      4,
+			colors: []Color{"#0000ff", "#000000", "#00ff00", "#ff0000"},
+		},
+		{
+			name:   "merged",
+			img:    stripes([]Color{"#000000", "#020202", "#ffffff", "#fdfdfd"}, []int{10, 10, 10, 10}),
+			n:      2,
+			colors: []Color{"#010101", "#fefefe"},
+		},
+		{
+			name:   "transparent",
+			img:    image.NewRGBA(image.Rect(0, 0, 4, 4)),
+			n:      4,
+			colors: []Color{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.colors, ExtractPalette(tc.img, tc.n))
+		})
+	}
+}
+
+func TestImageTheme(t *testing.T) {
+	testCases := []struct {
+		name  string
+		img   image.Image
+		light bool
+	}{
+		{name: "dark", img: stripes([]Color{"#1b2b34", "#6699cc", "#ec5f67", "#c0c5ce"}, []int{60, 20, 10, 10})},
+		{name: "light", img: stripes([]Color{"#eeeeee", "#ffcc00", "#555555"}, []int{70, 20, 10}), light: true},
+		{name: "gray", img: stripes([]Color{"#808080"}, []int{10}), light: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			theme, err := ImageTheme(tc.img)
+			require.NoError(t, err)
+			assert.Equal(t, tc.light, theme.Background.Luminance() > 0.5)
+			assert.GreaterOrEqual(t, theme.Foreground.Contrast(theme.Background), 7.0)
+			assert.GreaterOrEqual(t, theme.Foreground.Contrast(theme.Inactive), 4.5)
+			assert.GreaterOrEqual(t, theme.Accent.Contrast(theme.Background), 4.5)
+			assert.GreaterOrEqual(t, theme.Urgent.Contrast(theme.Background), 4.5)
+		})
+	}
+
+	_, err := ImageTheme(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	assert.EqualError(t, err, "image has no opaque pixels")
+}
+
+func TestWallpaperTheme(t *testing.T) {
+	dir := t.TempDir()
+	wallpaper := filepath.Join(dir, "wallpaper.png")
+	f, err := os.Create(wallpaper)
+	require.NoError(t, err)
+	assert.NoError(t, png.Encode(f, stripes([]Color{"#1b2b34", "#6699cc"}, []int{80, 20})))
+	f.Close()
+	pathFile := filepath.Join(dir, "current")
+	assert.NoError(t, os.WriteFile(pathFile, []byte(wallpaper+"\n"), 0644))
+
+	fallback := NordTheme
+	fallback.Font = "pango:DejaVu Sans Mono 11"
+
+	c := New("config.go")
+	c.WallpaperTheme(pathFile, fallback)
+	assert.Equal(t, "wallpaper", c.theme.Name)
+	assert.Equal(t, fallback.Font, c.theme.Font)
+	assert.Empty(t, c.Problems())
+
+	c = New("config.go")
+	line := currentLine() + 1
+	c.WallpaperTheme(filepath.Join(dir, "missing"), fallback)
+	assert.Equal(t, "nord", c.theme.Name)
+	problems := c.Problems()
+	require.Len(t, problems, 1)
+	assert.True(t, problems[0].Warning)
+	assert.Equal(t, line, problems[0].Source.Line)
+	assert.Contains(t, problems[0].Message, "using the nord theme: open ")
+}
+
+func TestApplyReadsWallpaperAgain(t *testing.T) {
+	dir := t.TempDir()
+	writeImage := func(name string, colors []Color) string {
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		require.NoError(t, err)
+		defer f.Close()
+		require.NoError(t, png.Encode(f, stripes(colors, []int{80, 20})))
+		return file
+	}
+	dark := writeImage("dark.png", []Color{"#1b2b34", "#6699cc"})
+	light := writeImage("light.png", []Color{"#eeeeee", "#ffcc00"})
+	pathFile := filepath.Join(dir, "current")
+	require.NoError(t, os.WriteFile(pathFile, []byte(dark), 0644))
+
+	c := New("config.go")
+	c.WallpaperTheme(pathFile, NordTheme)
+	c.ThemeFile(filepath.Join(dir, "rofi.rasi"), func(t Theme) Generator {
+		return &RofiTheme{Theme: t}
+	})
+	darkTheme := *c.theme
+
+	// the picker writes the new wallpaper after the config was built
+	require.NoError(t, os.WriteFile(pathFile, []byte(light), 0644))
+	configPath := filepath.Join(dir, "config")
+	require.NoError(t, c.apply(configPath))
+
+	lightTheme, err := LoadImageTheme(light)
+	require.NoError(t, err)
+	assert.NotEqual(t, darkTheme.Background, lightTheme.Background)
+	b, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "client.background "+string(lightTheme.Background))
+	assert.NotContains(t, string(b), string(darkTheme.Background))
+	b, err = os.ReadFile(filepath.Join(dir, "rofi.rasi"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "background: "+string(lightTheme.Background)+";")
+}
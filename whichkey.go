@@ -0,0 +1,158 @@
+package i3config
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+)
+
+// WhichKeyRenderer shows the bindings available in a mode while it is
+// active. Hide is called when i3 returns to the default mode.
+type WhichKeyRenderer interface {
+	Show(mode string, entries CheatSheet) error
+	Hide() error
+}
+
+// WhichKey starts a helper with i3 that shows the bindings of a mode or chord
+// while it is active. The bindings and their descriptions come from the
+// config's cheat sheet.
+func (c *Config) WhichKey(r WhichKeyRenderer) {
+	c.OnStartup(c.ExecFunc(func() error {
+		return c.whichKey(r)
+	}).NoStartupID())
+}
+
+func (c *Config) whichKey(r WhichKeyRenderer) error {
+	modes := whichKeyModes(c.CheatSheet(), c.variables())
+	return subscribeForever([]string{"mode"}, func(e *I3msgEvent) error {
+		if e.Mode == nil {
+			return nil
+		}
+		if e.Mode.Change == "default" {
+			return r.Hide()
+		}
+		return r.Show(e.Mode.Change, modes[e.Mode.Change])
+	})
+}
+
+// whichKeyModes groups the entries by mode name as reported by i3.
+func whichKeyModes(sheet CheatSheet, vars map[string]string) map[string]CheatSheet {
+	modes := map[string]CheatSheet{}
+	for _, e := range sheet {
+		mode := substituteVariables(e.Mode, vars)
+		modes[mode] = append(modes[mode], e)
+	}
+	return modes
+}
+
+// whichKeyLines formats entries as aligned key and description columns.
+func whichKeyLines(entries CheatSheet) []string {
+	width := 0
+	for _, e := range entries {
+		if w := len(whichKeyKey(e)); w > width {
+			width = w
+		}
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = fmt.Sprintf("%-*s  %s", width, whichKeyKey(e), e.Description)
+	}
+	return lines
+}
+
+// whichKeyKey is the key pressed next, the second key of a chord.
+func whichKeyKey(e *CheatSheetEntry) string {
+	return e.Keys[len(e.Keys)-1].String()
+}
+
+type whichKeyNotification struct {
+	id uint32
+}
+
+// WhichKeyNotification shows the bindings as a desktop notification that is
+// closed when the mode is left.
+func WhichKeyNotification() WhichKeyRenderer {
+	return &whichKeyNotification{}
+}
+
+func (n *whichKeyNotification) Show(mode string, entries CheatSheet) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call(
+		"org.freedesktop.Notifications.Notify",
+		0,
+		"i3config",
+		n.id,
+		"",
+		mode,
+		strings.Join(whichKeyLines(entries), "\n"),
+		make([]string, 0),
+		map[string]dbus.Variant{},
+		// never expire, the notification is closed by Hide
+		int32(0),
+	)
+	if call.Err != nil {
+		return call.Err
+	}
+	return call.Store(&n.id)
+}
+
+func (n *whichKeyNotification) Hide() error {
+	if n.id == 0 {
+		return nil
+	}
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	err = obj.Call("org.freedesktop.Notifications.CloseNotification", 0, n.id).Err
+	n.id = 0
+	return err
+}
+
+type whichKeyMenu struct {
+	menu *Argv
+}
+
+// WhichKeyMenu shows the bindings in a dmenu style program, like
+// Args("rofi", "-dmenu", "-i"), that reads choices from stdin and prints the
+// selected one. Selecting a binding runs its commands, closing the menu
+// leaves the mode.
+func WhichKeyMenu(menu *Argv) WhichKeyRenderer {
+	return &whichKeyMenu{menu: menu}
+}
+
+func (m *whichKeyMenu) Show(mode string, entries CheatSheet) error {
+	lines := whichKeyLines(entries)
+	cmd := m.menu.cmd()
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
+	b, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); ok {
+		return I3msg(Mode("default"))
+	} else if err != nil {
+		return err
+	}
+	selected := strings.TrimRight(string(b), "\n")
+	for i, line := range lines {
+		if line == selected {
+			return I3msg(entries[i].commands...)
+		}
+	}
+	return I3msg(Mode("default"))
+}
+
+// Hide does nothing, the menu holds the keyboard so the mode can only change
+// once it has closed.
+func (m *whichKeyMenu) Hide() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package i3config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhichKeyModes(t *testing.T) {
+	c := New("config.go")
+	c.Set("$mod", "Mod4")
+	c.Set("$mode_resize", "resize")
+	c.BindSym("$mod+q", Kill)
+	c.BindChord("$mod+o", "f", Exec("firefox")).Describe("Browser")
+	c.BindChord("$mod+o", "Shift+t", Exec("thunderbird")).Describe("Mail")
+	c.Mode("$mode_resize", func(c *Config) {
+		c.BindSym("h", ResizeShrink(Width, 10)).Describe("Narrower")
+		c.BindSym("Escape", Mode("default"))
+	})
+	c.applyChords()
+
+	modes := whichKeyModes(c.CheatSheet(), c.variables())
+
+	assert.Equal(t, []string{
+		"h       Narrower",
+		`Escape  mode "default"`,
+	}, whichKeyLines(modes["resize"]))
+	assert.Equal(t, []string{
+		"f        Browser",
+		"Shift+t  Mail",
+		`Escape   mode "default"`,
+	}, whichKeyLines(modes["Chord: Mod4+o"]))
+}
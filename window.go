@@ -18,6 +18,10 @@ const (
 	PopupMenu    WindowType = "popup_menu"
 	Tooltip      WindowType = "tooltip"
 	Notification WindowType = "notification"
+
+	// Dock and UnknownWindow only appear in get_tree output.
+	Dock          WindowType = "dock"
+	UnknownWindow WindowType = "unknown"
 )
 
 type Urgent string
@@ -43,20 +47,46 @@ type Criteria struct {
 
 }
 
+// String returns the criteria as used in a command.
 func (c *Criteria) String() string {
+	return c.format(quoteCommand)
+}
+
+func (c *Criteria) format(quote func(string) string) string {
 	ret := []string{}
 
 	EachKey(c, func(key, value string) {
-		if value != "" {
-			ret = append(ret, key+"="+escapeString(value))
+		if value == "" {
+			return
 		}
+		if key == "floating" || key == "tiling" {
+			ret = append(ret, key)
+			return
+		}
+		ret = append(ret, key+"="+quote(value))
 	})
 
 	return strings.Join(ret, " ")
 }
 
-func (c *Config) ForWindow(criteria Criteria, border *Command) {
-	c.raw(fmt.Sprintf("for_window [%s] %s", criteria.String(), border.Generate()))
+type ForWindowType struct {
+	criteria Criteria
+	commands []*Command
+}
+
+func (c *Config) ForWindow(criteria Criteria, commands ...*Command) {
+	c.AddLine(&ForWindowType{
+		criteria: criteria,
+		commands: commands,
+	})
+}
+
+func (f *ForWindowType) Generate() string {
+	strCommands := []string{}
+	for _, cmd := range f.commands {
+		strCommands = append(strCommands, cmd.Generate())
+	}
+	return fmt.Sprintf("for_window [%s] %s", f.criteria.format(quoteConfig), strings.Join(strCommands, ", "))
 }
 
 func (c *Config) FocusFollowsMouse(follow bool) {